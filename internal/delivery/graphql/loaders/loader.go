@@ -0,0 +1,79 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const batchWait = 2 * time.Millisecond
+
+// Loader collects the keys requested by concurrently running resolvers and
+// resolves them with one fetch call. Results are cached for the lifetime of
+// the loader, which is a single GraphQL operation.
+type Loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+	wait  time.Duration
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending map[K]*result[V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func NewLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch: fetch,
+		wait:  batchWait,
+		cache: make(map[K]*result[V]),
+	}
+}
+
+// Load returns the value for the key; keys missing from the fetch result
+// resolve to the zero value.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	res, cached := l.cache[key]
+	if !cached {
+		res = &result[V]{done: make(chan struct{})}
+		l.cache[key] = res
+
+		if l.pending == nil {
+			l.pending = make(map[K]*result[V])
+			time.AfterFunc(l.wait, l.dispatch)
+		}
+		l.pending[key] = res
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-res.done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (l *Loader[K, V]) dispatch() {
+	l.mu.Lock()
+	batch := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	keys := make([]K, 0, len(batch))
+	for key := range batch {
+		keys = append(keys, key)
+	}
+
+	values, err := l.fetch(keys)
+	for key, res := range batch {
+		res.value, res.err = values[key], err
+		close(res.done)
+	}
+}
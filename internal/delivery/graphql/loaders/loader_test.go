@@ -0,0 +1,55 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_BatchesConcurrentLoads(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]int
+	)
+	loader := NewLoader(func(keys []int) (map[int]int, error) {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+
+		result := make(map[int]int, len(keys))
+		for _, key := range keys {
+			result[key] = key * 10
+		}
+		return result, nil
+	})
+	loader.wait = 50 * time.Millisecond
+
+	var wg sync.WaitGroup
+	values := make([]int, 50)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := loader.Load(context.Background(), i%5)
+			assert.NoError(t, err)
+			values[i] = value
+		}(i)
+	}
+	wg.Wait()
+
+	for i, value := range values {
+		assert.Equal(t, i%5*10, value)
+	}
+
+	require.Len(t, batches, 1)
+	assert.Len(t, batches[0], 5)
+
+	value, err := loader.Load(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, 30, value)
+	assert.Len(t, batches, 1, "cached keys must not be fetched again")
+}
@@ -0,0 +1,168 @@
+package loaders
+
+import (
+	"context"
+
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/services"
+)
+
+type contextKey struct{}
+
+// LevelKey identifies the first page of one level of a thread: the replies
+// of a comment or the top-level comments of a post.
+type LevelKey struct {
+	ID        string
+	Limit     int
+	SortOrder string
+}
+
+// VoteKey identifies the vote of one voter on a post or comment.
+type VoteKey struct {
+	TargetID string
+	Voter    string
+}
+
+// ReactionKey identifies the reactions on a comment as seen by one user.
+type ReactionKey struct {
+	CommentID string
+	User      string
+}
+
+// FollowKey identifies whether one user follows a post.
+type FollowKey struct {
+	PostID string
+	User   string
+}
+
+// Loaders holds the per-operation batch loaders used by field resolvers.
+type Loaders struct {
+	Posts        *Loader[string, *models.Post]
+	Replies      *Loader[LevelKey, *repositories.CommentPage]
+	TopLevel     *Loader[LevelKey, *repositories.CommentPage]
+	CommentVotes *Loader[VoteKey, int]
+	PostVotes    *Loader[VoteKey, int]
+	Reactions    *Loader[ReactionKey, []*models.ReactionSummary]
+	Followers    *Loader[string, int]
+	Following    *Loader[FollowKey, bool]
+}
+
+func New(postService *services.PostService, commentService *services.CommentService, voteService *services.VoteService, reactionService *services.ReactionService, followService *services.FollowService) *Loaders {
+	return &Loaders{
+		Posts:        NewLoader(postService.GetPostsByIDs),
+		Replies:      NewLoader(levelFetcher(commentService.GetRepliesPages)),
+		TopLevel:     NewLoader(levelFetcher(commentService.GetTopLevelPages)),
+		CommentVotes: NewLoader(voteFetcher(voteService.GetCommentVotes)),
+		PostVotes:    NewLoader(voteFetcher(voteService.GetPostVotes)),
+		Reactions:    NewLoader(reactionFetcher(reactionService.GetReactions)),
+		Followers:    NewLoader(followService.GetFollowersCounts),
+		Following:    NewLoader(followFetcher(followService.GetFollowedPosts)),
+	}
+}
+
+func With(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, contextKey{}, loaders)
+}
+
+func For(ctx context.Context) *Loaders {
+	return ctx.Value(contextKey{}).(*Loaders)
+}
+
+// levelFetcher groups the requested levels by page size and sort order,
+// issuing one repository call per group.
+func levelFetcher(fetch func(ids []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error)) func([]LevelKey) (map[LevelKey]*repositories.CommentPage, error) {
+	type group struct {
+		limit     int
+		sortOrder string
+	}
+
+	return func(keys []LevelKey) (map[LevelKey]*repositories.CommentPage, error) {
+		groups := make(map[group][]string)
+		for _, key := range keys {
+			g := group{limit: key.Limit, sortOrder: key.SortOrder}
+			groups[g] = append(groups[g], key.ID)
+		}
+
+		result := make(map[LevelKey]*repositories.CommentPage, len(keys))
+		for g, ids := range groups {
+			pages, err := fetch(ids, g.limit, g.sortOrder)
+			if err != nil {
+				return nil, err
+			}
+			for id, page := range pages {
+				result[LevelKey{ID: id, Limit: g.limit, SortOrder: g.sortOrder}] = page
+			}
+		}
+		return result, nil
+	}
+}
+
+// voteFetcher groups the requested votes by voter, issuing one repository
+// call per voter. Missing votes load as 0.
+func voteFetcher(fetch func(voter string, ids []string) (map[string]int, error)) func([]VoteKey) (map[VoteKey]int, error) {
+	return func(keys []VoteKey) (map[VoteKey]int, error) {
+		groups := make(map[string][]string)
+		for _, key := range keys {
+			groups[key.Voter] = append(groups[key.Voter], key.TargetID)
+		}
+
+		result := make(map[VoteKey]int, len(keys))
+		for voter, ids := range groups {
+			votes, err := fetch(voter, ids)
+			if err != nil {
+				return nil, err
+			}
+			for id, value := range votes {
+				result[VoteKey{TargetID: id, Voter: voter}] = value
+			}
+		}
+		return result, nil
+	}
+}
+
+// reactionFetcher groups the requested comments by user, issuing one
+// repository call per user.
+func reactionFetcher(fetch func(commentIDs []string, user string) (map[string][]*models.ReactionSummary, error)) func([]ReactionKey) (map[ReactionKey][]*models.ReactionSummary, error) {
+	return func(keys []ReactionKey) (map[ReactionKey][]*models.ReactionSummary, error) {
+		groups := make(map[string][]string)
+		for _, key := range keys {
+			groups[key.User] = append(groups[key.User], key.CommentID)
+		}
+
+		result := make(map[ReactionKey][]*models.ReactionSummary, len(keys))
+		for user, ids := range groups {
+			summaries, err := fetch(ids, user)
+			if err != nil {
+				return nil, err
+			}
+			for id, list := range summaries {
+				result[ReactionKey{CommentID: id, User: user}] = list
+			}
+		}
+		return result, nil
+	}
+}
+
+// followFetcher groups the requested posts by user, issuing one repository
+// call per user. Posts the user does not follow load as false.
+func followFetcher(fetch func(user string, postIDs []string) (map[string]bool, error)) func([]FollowKey) (map[FollowKey]bool, error) {
+	return func(keys []FollowKey) (map[FollowKey]bool, error) {
+		groups := make(map[string][]string)
+		for _, key := range keys {
+			groups[key.User] = append(groups[key.User], key.PostID)
+		}
+
+		result := make(map[FollowKey]bool, len(keys))
+		for user, ids := range groups {
+			followed, err := fetch(user, ids)
+			if err != nil {
+				return nil, err
+			}
+			for id, following := range followed {
+				result[FollowKey{PostID: id, User: user}] = following
+			}
+		}
+		return result, nil
+	}
+}
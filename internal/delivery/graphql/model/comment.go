@@ -8,4 +8,16 @@ type Comment struct {
 	Author       string  `json:"author"`
 	CreatedAt    string  `json:"createdAt"`
 	RepliesCount int     `json:"repliesCount"`
+	EditedAt     *string `json:"editedAt,omitempty"`
+	Deleted      bool    `json:"deleted"`
+	Depth        int     `json:"depth"`
+
+	DescendantsCount int      `json:"descendantsCount"`
+	Score            int      `json:"score"`
+	Upvotes          int      `json:"upvotes"`
+	Downvotes        int      `json:"downvotes"`
+	Pinned           bool     `json:"pinned"`
+	Locked           bool     `json:"locked"`
+	Mentions         []string `json:"mentions"`
+	LastActivityAt   string   `json:"lastActivityAt"`
 }
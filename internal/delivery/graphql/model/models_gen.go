@@ -20,9 +20,49 @@ type CommentEdge struct {
 	Cursor string   `json:"cursor"`
 }
 
+type CommentRevision struct {
+	Text      string `json:"text"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type CommentTree struct {
+	Roots        []*CommentTreeNode `json:"roots"`
+	HasMoreRoots bool               `json:"hasMoreRoots"`
+	NextCursor   *string            `json:"nextCursor,omitempty"`
+}
+
+type CommentTreeNode struct {
+	Comment         *Comment           `json:"comment"`
+	Children        []*CommentTreeNode `json:"children"`
+	HasMoreChildren bool               `json:"hasMoreChildren"`
+	NextCursor      *string            `json:"nextCursor,omitempty"`
+}
+
 type Mutation struct {
 }
 
+type Notification struct {
+	ID        string           `json:"id"`
+	Kind      NotificationKind `json:"kind"`
+	Actor     string           `json:"actor"`
+	PostID    string           `json:"postId"`
+	CommentID string           `json:"commentId"`
+	Comment   *Comment         `json:"comment,omitempty"`
+	CreatedAt string           `json:"createdAt"`
+	Read      bool             `json:"read"`
+}
+
+type NotificationConnection struct {
+	Edges       []*NotificationEdge `json:"edges"`
+	PageInfo    *PageInfo           `json:"pageInfo"`
+	UnreadCount int                 `json:"unreadCount"`
+}
+
+type NotificationEdge struct {
+	Node   *Notification `json:"node"`
+	Cursor string        `json:"cursor"`
+}
+
 type PageInfo struct {
 	HasNextPage     bool    `json:"hasNextPage"`
 	EndCursor       *string `json:"endCursor,omitempty"`
@@ -30,6 +70,17 @@ type PageInfo struct {
 	StartCursor     *string `json:"startCursor,omitempty"`
 }
 
+type PostConnection struct {
+	Edges      []*PostEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type PostEdge struct {
+	Node   *Post  `json:"node"`
+	Cursor string `json:"cursor"`
+}
+
 type PostWithComments struct {
 	Post          *Post      `json:"post"`
 	Comments      []*Comment `json:"comments"`
@@ -39,6 +90,150 @@ type PostWithComments struct {
 type Query struct {
 }
 
+type ReactionSummary struct {
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reactedByMe"`
+}
+
+type Subscription struct {
+}
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type VoteResult struct {
+	TargetID  string `json:"targetId"`
+	Score     int    `json:"score"`
+	Upvotes   int    `json:"upvotes"`
+	Downvotes int    `json:"downvotes"`
+	MyVote    int    `json:"myVote"`
+}
+
+type CommentSortOrder string
+
+const (
+	CommentSortOrderAsc          CommentSortOrder = "ASC"
+	CommentSortOrderDesc         CommentSortOrder = "DESC"
+	CommentSortOrderTop          CommentSortOrder = "TOP"
+	CommentSortOrderHot          CommentSortOrder = "HOT"
+	CommentSortOrderLastActivity CommentSortOrder = "LAST_ACTIVITY"
+)
+
+var AllCommentSortOrder = []CommentSortOrder{
+	CommentSortOrderAsc,
+	CommentSortOrderDesc,
+	CommentSortOrderTop,
+	CommentSortOrderHot,
+	CommentSortOrderLastActivity,
+}
+
+func (e CommentSortOrder) IsValid() bool {
+	switch e {
+	case CommentSortOrderAsc, CommentSortOrderDesc, CommentSortOrderTop, CommentSortOrderHot, CommentSortOrderLastActivity:
+		return true
+	}
+	return false
+}
+
+func (e CommentSortOrder) String() string {
+	return string(e)
+}
+
+func (e *CommentSortOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentSortOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentSortOrder", str)
+	}
+	return nil
+}
+
+func (e CommentSortOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CommentSortOrder) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CommentSortOrder) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationKind string
+
+const (
+	NotificationKindReply          NotificationKind = "REPLY"
+	NotificationKindMention        NotificationKind = "MENTION"
+	NotificationKindPostComment    NotificationKind = "POST_COMMENT"
+	NotificationKindFollowedThread NotificationKind = "FOLLOWED_THREAD"
+	NotificationKindFollowedPost   NotificationKind = "FOLLOWED_POST"
+)
+
+var AllNotificationKind = []NotificationKind{
+	NotificationKindReply,
+	NotificationKindMention,
+	NotificationKindPostComment,
+	NotificationKindFollowedThread,
+	NotificationKindFollowedPost,
+}
+
+func (e NotificationKind) IsValid() bool {
+	switch e {
+	case NotificationKindReply, NotificationKindMention, NotificationKindPostComment, NotificationKindFollowedThread, NotificationKindFollowedPost:
+		return true
+	}
+	return false
+}
+
+func (e NotificationKind) String() string {
+	return string(e)
+}
+
+func (e *NotificationKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationKind", str)
+	}
+	return nil
+}
+
+func (e NotificationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type SortOrder string
 
 const (
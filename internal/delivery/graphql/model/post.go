@@ -7,4 +7,16 @@ type Post struct {
 	Author        string `json:"author"`
 	AllowComments bool   `json:"allowComments"`
 	CreatedAt     string `json:"createdAt"`
+
+	CommentsClosedBy *string  `json:"commentsClosedBy,omitempty"`
+	CommentsClosedAt *string  `json:"commentsClosedAt,omitempty"`
+	MaxCommentDepth  *int     `json:"maxCommentDepth,omitempty"`
+	CommentsTotal    int      `json:"commentsTotal"`
+	Score            int      `json:"score"`
+	Upvotes          int      `json:"upvotes"`
+	Downvotes        int      `json:"downvotes"`
+	Archived         bool     `json:"archived"`
+	ArchivedAt       *string  `json:"archivedAt,omitempty"`
+	Tags             []string `json:"tags"`
+	Category         *string  `json:"category,omitempty"`
 }
@@ -4,22 +4,36 @@ package graphql
 
 import (
 	"context"
+	"errors"
 	"posts_comments_service/internal/delivery/graphql/generated"
+	"posts_comments_service/internal/delivery/graphql/loaders"
 	"posts_comments_service/internal/delivery/graphql/model"
 	"posts_comments_service/internal/domain/constants"
 	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/pagination"
+	"posts_comments_service/internal/domain/repositories"
 	"posts_comments_service/internal/domain/services"
 )
 
 type Resolver struct {
-	postService    *services.PostService
-	commentService *services.CommentService
+	postService         *services.PostService
+	commentService      *services.CommentService
+	voteService         *services.VoteService
+	reactionService     *services.ReactionService
+	notificationService *services.NotificationService
+	followService       *services.FollowService
+	cursors             *pagination.Codec
 }
 
-func NewResolver(postService *services.PostService, commentService *services.CommentService) *Resolver {
+func NewResolver(postService *services.PostService, commentService *services.CommentService, voteService *services.VoteService, reactionService *services.ReactionService, notificationService *services.NotificationService, followService *services.FollowService, cursors *pagination.Codec) *Resolver {
 	return &Resolver{
-		postService:    postService,
-		commentService: commentService,
+		postService:         postService,
+		commentService:      commentService,
+		voteService:         voteService,
+		reactionService:     reactionService,
+		notificationService: notificationService,
+		followService:       followService,
+		cursors:             cursors,
 	}
 }
 
@@ -32,6 +46,59 @@ func (r *mutationResolver) CreatePost(ctx context.Context, title string, content
 	return convertDomainPostToModel(domainPost), nil
 }
 
+// UpdatePost is the resolver for the updatePost field.
+func (r *mutationResolver) UpdatePost(ctx context.Context, id string, title *string, content *string) (*model.Post, error) {
+	domainPost, err := r.postService.UpdatePost(id, title, content)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainPostToModel(domainPost), nil
+}
+
+// DeletePost is the resolver for the deletePost field.
+func (r *mutationResolver) DeletePost(ctx context.Context, id string) (bool, error) {
+	if err := r.postService.DeletePost(id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetCommentsEnabled is the resolver for the setCommentsEnabled field.
+func (r *mutationResolver) SetCommentsEnabled(ctx context.Context, postID string, enabled bool, moderator *string) (*model.Post, error) {
+	domainPost, err := r.postService.SetCommentsEnabled(postID, enabled, moderator)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainPostToModel(domainPost), nil
+}
+
+// SetMaxCommentDepth is the resolver for the setMaxCommentDepth field.
+func (r *mutationResolver) SetMaxCommentDepth(ctx context.Context, postID string, maxDepth *int) (*model.Post, error) {
+	domainPost, err := r.postService.SetMaxCommentDepth(postID, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainPostToModel(domainPost), nil
+}
+
+// SetPostTags is the resolver for the setPostTags field.
+func (r *mutationResolver) SetPostTags(ctx context.Context, postID string, tags []string) (*model.Post, error) {
+	domainPost, err := r.postService.SetTags(postID, tags)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainPostToModel(domainPost), nil
+}
+
+// SetPostCategory is the resolver for the setPostCategory field.
+func (r *mutationResolver) SetPostCategory(ctx context.Context, postID string, category *string) (*model.Post, error) {
+	domainPost, err := r.postService.SetCategory(postID, category)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainPostToModel(domainPost), nil
+}
+
 // CreateComment is the resolver for the createComment field.
 func (r *mutationResolver) CreateComment(ctx context.Context, postID string, parentID *string, text string, author string) (*model.Comment, error) {
 	domainComment, err := r.commentService.AddComment(postID, author, text, parentID)
@@ -41,23 +108,257 @@ func (r *mutationResolver) CreateComment(ctx context.Context, postID string, par
 	return convertDomainCommentToModel(domainComment), nil
 }
 
-// Query resolvers
-func (r *queryResolver) Posts(ctx context.Context, after *string, first *int, sortOrder *model.SortOrder) ([]*model.Post, error) {
-	limit := constants.DefaultLimit
-	if first != nil {
-		limit = *first
+// EditComment is the resolver for the editComment field.
+func (r *mutationResolver) EditComment(ctx context.Context, id string, text string) (*model.Comment, error) {
+	domainComment, err := r.commentService.EditComment(id, text)
+	if err != nil {
+		return nil, err
 	}
+	return convertDomainCommentToModel(domainComment), nil
+}
 
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (bool, error) {
+	if err := r.commentService.DeleteComment(id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Vote is the resolver for the vote field.
+func (r *mutationResolver) Vote(ctx context.Context, targetID string, value int, voter string) (*model.VoteResult, error) {
+	result, err := r.voteService.Vote(targetID, voter, value)
+	if err != nil {
+		return nil, err
+	}
+	return &model.VoteResult{
+		TargetID:  result.TargetID,
+		Score:     result.Score,
+		Upvotes:   result.Upvotes,
+		Downvotes: result.Downvotes,
+		MyVote:    result.Value,
+	}, nil
+}
+
+// AddReaction is the resolver for the addReaction field.
+func (r *mutationResolver) AddReaction(ctx context.Context, commentID string, emoji string, user string) (*model.Comment, error) {
+	comment, err := r.reactionService.AddReaction(commentID, emoji, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentToModel(comment), nil
+}
+
+// RemoveReaction is the resolver for the removeReaction field.
+func (r *mutationResolver) RemoveReaction(ctx context.Context, commentID string, emoji string, user string) (*model.Comment, error) {
+	comment, err := r.reactionService.RemoveReaction(commentID, emoji, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentToModel(comment), nil
+}
+
+// SetThreadLocked is the resolver for the setThreadLocked field.
+func (r *mutationResolver) SetThreadLocked(ctx context.Context, commentID string, locked bool, user string) (*model.Comment, error) {
+	comment, err := r.commentService.SetThreadLocked(commentID, locked, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentToModel(comment), nil
+}
+
+// PinComment is the resolver for the pinComment field.
+func (r *mutationResolver) PinComment(ctx context.Context, commentID string, user string) (*model.Comment, error) {
+	comment, err := r.commentService.PinComment(commentID, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentToModel(comment), nil
+}
+
+// UnpinComment is the resolver for the unpinComment field.
+func (r *mutationResolver) UnpinComment(ctx context.Context, commentID string, user string) (*model.Comment, error) {
+	comment, err := r.commentService.UnpinComment(commentID, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentToModel(comment), nil
+}
+
+// MarkNotificationsRead is the resolver for the markNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, user string, ids []string) (int, error) {
+	return r.notificationService.MarkRead(user, ids)
+}
+
+// FollowPost is the resolver for the followPost field.
+func (r *mutationResolver) FollowPost(ctx context.Context, postID string, user string) (*model.Post, error) {
+	post, err := r.followService.FollowPost(postID, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainPostToModel(post), nil
+}
+
+// UnfollowPost is the resolver for the unfollowPost field.
+func (r *mutationResolver) UnfollowPost(ctx context.Context, postID string, user string) (*model.Post, error) {
+	post, err := r.followService.UnfollowPost(postID, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainPostToModel(post), nil
+}
+
+// FollowThread is the resolver for the followThread field.
+func (r *mutationResolver) FollowThread(ctx context.Context, commentID string, user string) (*model.Comment, error) {
+	comment, err := r.followService.FollowThread(commentID, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentToModel(comment), nil
+}
+
+// UnfollowThread is the resolver for the unfollowThread field.
+func (r *mutationResolver) UnfollowThread(ctx context.Context, commentID string, user string) (*model.Comment, error) {
+	comment, err := r.followService.UnfollowThread(commentID, user)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentToModel(comment), nil
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *commentResolver) Revisions(ctx context.Context, obj *model.Comment) ([]*model.CommentRevision, error) {
+	revisions, err := r.commentService.GetRevisions(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.CommentRevision, len(revisions))
+	for i, revision := range revisions {
+		result[i] = &model.CommentRevision{
+			Text:      revision.Text,
+			CreatedAt: revision.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+// Post is the resolver for the post field.
+func (r *commentResolver) Post(ctx context.Context, obj *model.Comment) (*model.Post, error) {
+	domainPost, err := loaders.For(ctx).Posts.Load(ctx, obj.PostID)
+	if err != nil {
+		return nil, err
+	}
+	if domainPost == nil {
+		return nil, repositories.ErrNotFound
+	}
+	return convertDomainPostToModel(domainPost), nil
+}
+
+// Ancestors is the resolver for the ancestors field.
+func (r *commentResolver) Ancestors(ctx context.Context, obj *model.Comment) ([]*model.Comment, error) {
+	ancestors, err := r.commentService.GetAncestors(obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentsToModel(ancestors), nil
+}
+
+// MyVote is the resolver for the myVote field.
+func (r *commentResolver) MyVote(ctx context.Context, obj *model.Comment, voter string) (int, error) {
+	return loaders.For(ctx).CommentVotes.Load(ctx, loaders.VoteKey{TargetID: obj.ID, Voter: voter})
+}
+
+// Reactions is the resolver for the reactions field.
+func (r *commentResolver) Reactions(ctx context.Context, obj *model.Comment, user *string) ([]*model.ReactionSummary, error) {
+	key := loaders.ReactionKey{CommentID: obj.ID}
+	if user != nil {
+		key.User = *user
+	}
+
+	summaries, err := loaders.For(ctx).Reactions.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.ReactionSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, &model.ReactionSummary{
+			Emoji:       summary.Emoji,
+			Count:       summary.Count,
+			ReactedByMe: summary.ReactedByMe,
+		})
+	}
+	return result, nil
+}
+
+// Replies is the resolver for the replies field.
+func (r *commentResolver) Replies(ctx context.Context, obj *model.Comment, first *int, after *string, sortOrder *model.CommentSortOrder) (*model.CommentConnection, error) {
+	return r.levelConnection(ctx, loaders.For(ctx).Replies, obj.PostID, &obj.ID, first, after, sortOrder)
+}
+
+// FollowersCount is the resolver for the followersCount field.
+func (r *postResolver) FollowersCount(ctx context.Context, obj *model.Post) (int, error) {
+	return loaders.For(ctx).Followers.Load(ctx, obj.ID)
+}
+
+// ViewerIsFollowing is the resolver for the viewerIsFollowing field.
+func (r *postResolver) ViewerIsFollowing(ctx context.Context, obj *model.Post, user string) (bool, error) {
+	return loaders.For(ctx).Following.Load(ctx, loaders.FollowKey{PostID: obj.ID, User: user})
+}
+
+// Comments is the resolver for the comments field.
+func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int, after *string, sortOrder *model.CommentSortOrder) (*model.CommentConnection, error) {
+	return r.levelConnection(ctx, loaders.For(ctx).TopLevel, obj.ID, nil, first, after, sortOrder)
+}
+
+// MyVote is the resolver for the myVote field.
+func (r *postResolver) MyVote(ctx context.Context, obj *model.Post, voter string) (int, error) {
+	return loaders.For(ctx).PostVotes.Load(ctx, loaders.VoteKey{TargetID: obj.ID, Voter: voter})
+}
+
+// Query resolvers
+func (r *queryResolver) Posts(ctx context.Context, after *string, first *int, before *string, last *int, sortOrder *model.SortOrder, tag *string, category *string) (*model.PostConnection, error) {
 	order := "DESC"
 	if sortOrder != nil {
 		order = string(*sortOrder)
 	}
 
-	domainPosts, err := r.postService.GetPosts(limit, after, order)
+	var filter repositories.PostFilter
+	if tag != nil {
+		filter.Tag = *tag
+	}
+	if category != nil {
+		filter.Category = *category
+	}
+
+	scope := postsScope(order, filter)
+	page, err := newPage(r.cursors, scope, first, after, last, before, order)
 	if err != nil {
 		return nil, err
 	}
-	return convertDomainPostsToModel(domainPosts), nil
+
+	domainPosts, hasMore, err := r.postService.GetPosts(filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := r.postService.GetPostsCount(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := convertToPostEdges(domainPosts, r.cursors, scope)
+	cursors := make([]string, len(edges))
+	for i, edge := range edges {
+		cursors[i] = edge.Cursor
+	}
+
+	return &model.PostConnection{
+		Edges:      edges,
+		PageInfo:   generatePageInfo(page, hasMore, cursors),
+		TotalCount: count,
+	}, nil
 }
 
 // Post is the resolver for the post field.
@@ -69,19 +370,29 @@ func (r *queryResolver) Post(ctx context.Context, id string) (*model.Post, error
 	return convertDomainPostToModel(domainPost), nil
 }
 
-// Comments is the resolver for the comments field.
-func (r *queryResolver) Comments(ctx context.Context, postID string, parentID *string, after *string, first *int, sortOrder *model.SortOrder) (*model.CommentConnection, error) {
-	limit := constants.DefaultLimit
-	if first != nil {
-		limit = *first
+// Comment is the resolver for the comment field.
+func (r *queryResolver) Comment(ctx context.Context, id string) (*model.Comment, error) {
+	domainComment, err := r.commentService.GetComment(id)
+	if err != nil {
+		return nil, err
 	}
+	return convertDomainCommentToModel(domainComment), nil
+}
 
+// Comments is the resolver for the comments field.
+func (r *queryResolver) Comments(ctx context.Context, postID string, parentID *string, after *string, first *int, before *string, last *int, sortOrder *model.CommentSortOrder) (*model.CommentConnection, error) {
 	order := "ASC"
 	if sortOrder != nil {
 		order = string(*sortOrder)
 	}
 
-	domainComments, hasMore, err := r.commentService.GetComments(postID, parentID, limit, after, order)
+	scope := commentsScope(postID, parentID, order)
+	page, err := newPage(r.cursors, scope, first, after, last, before, order)
+	if err != nil {
+		return nil, err
+	}
+
+	domainComments, hasMore, err := r.commentService.GetComments(postID, parentID, page)
 	if err != nil {
 		return nil, err
 	}
@@ -91,18 +402,125 @@ func (r *queryResolver) Comments(ctx context.Context, postID string, parentID *s
 		return nil, err
 	}
 
-	return &model.CommentConnection{
-		Edges:      convertToCommentEdges(domainComments),
-		PageInfo:   generatePageInfo(hasMore, domainComments),
-		TotalCount: count,
-	}, nil
+	return convertToCommentConnection(domainComments, page, hasMore, count, r.cursors, scope), nil
 }
 
 // CommentsCount is the resolver for the commentsCount field.
-func (r *queryResolver) CommentsCount(ctx context.Context, postID string, parentID *string) (int, error) {
+func (r *queryResolver) CommentsCount(ctx context.Context, postID string, parentID *string, allDepths *bool) (int, error) {
+	if allDepths != nil && *allDepths {
+		return r.commentService.GetSubtreeCount(postID, parentID)
+	}
 	return r.commentService.GetCommentsCount(postID, parentID)
 }
 
+// Tags is the resolver for the tags field.
+func (r *queryResolver) Tags(ctx context.Context, prefix *string, first *int) ([]*model.TagCount, error) {
+	var search string
+	if prefix != nil {
+		search = *prefix
+	}
+	limit := constants.DefaultLimit
+	if first != nil {
+		limit = *first
+	}
+
+	tags, err := r.postService.GetTags(search, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.TagCount, len(tags))
+	for i, tag := range tags {
+		result[i] = &model.TagCount{Tag: tag.Tag, Count: tag.Count}
+	}
+	return result, nil
+}
+
+// MentionsOf is the resolver for the mentionsOf field.
+func (r *queryResolver) MentionsOf(ctx context.Context, user string, first *int, after *string) (*model.CommentConnection, error) {
+	scope := mentionsScope(user)
+	page, err := newPage(r.cursors, scope, first, after, nil, nil, constants.SortDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	domainComments, hasMore, err := r.commentService.GetMentions(user, page)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := r.commentService.GetMentionsCount(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToCommentConnection(domainComments, page, hasMore, count, r.cursors, scope), nil
+}
+
+// Notifications is the resolver for the notifications field.
+func (r *queryResolver) Notifications(ctx context.Context, user string, first *int, after *string, unreadOnly *bool) (*model.NotificationConnection, error) {
+	unread := unreadOnly != nil && *unreadOnly
+	scope := notificationsScope(user, unread)
+	page, err := newPage(r.cursors, scope, first, after, nil, nil, constants.SortDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, hasMore, err := r.notificationService.GetNotifications(user, unread, page)
+	if err != nil {
+		return nil, err
+	}
+
+	unreadCount, err := r.notificationService.GetUnreadCount(user)
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]*model.NotificationEdge, len(notifications))
+	cursors := make([]string, len(notifications))
+	for i, notification := range notifications {
+		cursors[i] = r.cursors.Encode(scope, repositories.NotificationCursor(notification))
+		edges[i] = &model.NotificationEdge{
+			Node:   convertDomainNotificationToModel(notification),
+			Cursor: cursors[i],
+		}
+	}
+
+	return &model.NotificationConnection{
+		Edges:       edges,
+		PageInfo:    generatePageInfo(page, hasMore, cursors),
+		UnreadCount: unreadCount,
+	}, nil
+}
+
+// CommentTree is the resolver for the commentTree field.
+func (r *queryResolver) CommentTree(ctx context.Context, postID string, rootID *string, maxDepth *int, maxChildrenPerNode *int) (*model.CommentTree, error) {
+	depth := constants.DefaultTreeDepth
+	if maxDepth != nil {
+		depth = *maxDepth
+	}
+	children := constants.DefaultLimit
+	if maxChildrenPerNode != nil {
+		children = *maxChildrenPerNode
+	}
+
+	roots, hasMore, err := r.commentService.GetCommentTree(postID, rootID, depth, children)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &model.CommentTree{
+		Roots:        convertToCommentTreeNodes(roots, r.cursors),
+		HasMoreRoots: hasMore,
+	}
+	if hasMore && len(roots) > 0 {
+		last := roots[len(roots)-1].Comment
+		cursor := r.cursors.Encode(commentsScope(postID, nil, constants.SortAsc), repositories.CommentCursor(last))
+		tree.NextCursor = &cursor
+	}
+	return tree, nil
+}
+
 // PostWithComments is the resolver for the postWithComments field.
 func (r *queryResolver) PostWithComments(ctx context.Context, postID string, after *string, first *int) (*model.PostWithComments, error) {
 	limit := constants.DefaultLimit
@@ -115,12 +533,16 @@ func (r *queryResolver) PostWithComments(ctx context.Context, postID string, aft
 		return nil, err
 	}
 
-	comments, _, err := r.commentService.GetComments(postID, nil, limit, after, "ASC")
+	afterCursor, err := r.cursors.Decode(commentsScope(postID, nil, constants.SortAsc), after)
 	if err != nil {
 		return nil, err
 	}
 
-	repliesCountMap, err := r.commentService.GetRepliesCounts(postID)
+	comments, _, err := r.commentService.GetComments(postID, nil, repositories.Page{
+		Limit:     limit,
+		After:     afterCursor,
+		SortOrder: constants.SortAsc,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -132,21 +554,96 @@ func (r *queryResolver) PostWithComments(ctx context.Context, postID string, aft
 
 	return &model.PostWithComments{
 		Post:          convertDomainPostToModel(post),
-		Comments:      convertDomainCommentsToModelWithReplies(comments, repliesCountMap),
+		Comments:      convertDomainCommentsToModel(comments),
 		TotalComments: total,
 	}, nil
 }
 
+// CommentAdded is the resolver for the commentAdded field.
+func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID string, parentID *string) (<-chan *model.Comment, error) {
+	if _, err := r.postService.GetPost(postID); err != nil {
+		return nil, err
+	}
+
+	comments := r.commentService.SubscribeComments(ctx, postID, parentID)
+	out := make(chan *model.Comment, 1)
+
+	go func() {
+		defer close(out)
+		for comment := range comments {
+			select {
+			case out <- convertDomainCommentToModel(comment):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// NotificationAdded is the resolver for the notificationAdded field.
+func (r *subscriptionResolver) NotificationAdded(ctx context.Context, user string) (<-chan *model.Notification, error) {
+	notifications := r.notificationService.SubscribeNotifications(ctx, user)
+	out := make(chan *model.Notification, 1)
+
+	go func() {
+		defer close(out)
+		for notification := range notifications {
+			select {
+			case out <- convertDomainNotificationToModel(notification):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// Comment is the resolver for the comment field.
+func (r *notificationResolver) Comment(ctx context.Context, obj *model.Notification) (*model.Comment, error) {
+	comment, err := r.commentService.GetComment(obj.CommentID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return convertDomainCommentToModel(comment), nil
+}
+
+// Comment returns generated.CommentResolver implementation.
+func (r *Resolver) Comment() generated.CommentResolver { return &commentResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// Notification returns generated.NotificationResolver implementation.
+func (r *Resolver) Notification() generated.NotificationResolver { return &notificationResolver{r} }
+
+// Post returns generated.PostResolver implementation.
+func (r *Resolver) Post() generated.PostResolver { return &postResolver{r} }
+
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+// Subscription returns generated.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() generated.SubscriptionResolver { return &subscriptionResolver{r} }
+
+type commentResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type notificationResolver struct{ *Resolver }
+type postResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
 
 func convertDomainPostToModel(post *models.Post) *model.Post {
+	tags := post.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
 	return &model.Post{
 		ID:            post.ID,
 		Title:         post.Title,
@@ -154,66 +651,247 @@ func convertDomainPostToModel(post *models.Post) *model.Post {
 		Author:        post.Author,
 		AllowComments: post.AllowComments,
 		CreatedAt:     post.CreatedAt,
+
+		CommentsClosedBy: post.CommentsClosedBy,
+		CommentsClosedAt: post.CommentsClosedAt,
+		MaxCommentDepth:  post.MaxCommentDepth,
+		CommentsTotal:    post.CommentsTotal,
+		Score:            post.Score,
+		Upvotes:          post.Upvotes,
+		Downvotes:        post.Downvotes,
+		Archived:         post.ArchivedAt != nil,
+		ArchivedAt:       post.ArchivedAt,
+		Tags:             tags,
+		Category:         post.Category,
 	}
 }
-func convertDomainPostsToModel(posts []*models.Post) []*model.Post {
-	result := make([]*model.Post, len(posts))
+func convertToPostEdges(posts []*models.Post, cursors *pagination.Codec, scope string) []*model.PostEdge {
+	edges := make([]*model.PostEdge, len(posts))
 	for i, post := range posts {
-		result[i] = convertDomainPostToModel(post)
+		edges[i] = &model.PostEdge{
+			Node:   convertDomainPostToModel(post),
+			Cursor: cursors.Encode(scope, repositories.PostCursor(post)),
+		}
 	}
-	return result
+	return edges
 }
 func convertDomainCommentToModel(comment *models.Comment) *model.Comment {
-	return &model.Comment{
+	mentions := comment.Mentions
+	if mentions == nil {
+		mentions = []string{}
+	}
+
+	result := &model.Comment{
 		ID:           comment.ID,
 		PostID:       comment.PostID,
 		ParentID:     comment.ParentID,
 		Text:         comment.Text,
 		Author:       comment.Author,
 		CreatedAt:    comment.CreatedAt,
-		RepliesCount: 0,
+		RepliesCount: comment.RepliesCount,
+		EditedAt:     comment.EditedAt,
+		Deleted:      comment.Deleted,
+		Depth:        comment.Depth,
+
+		DescendantsCount: comment.DescendantsCount,
+		Score:            comment.Score,
+		Upvotes:          comment.Upvotes,
+		Downvotes:        comment.Downvotes,
+		Pinned:           comment.PinnedAt != nil,
+		Locked:           comment.Locked,
+		Mentions:         mentions,
+		LastActivityAt:   comment.LastActivityAt,
+	}
+	if comment.Deleted {
+		result.Text = constants.DeletedCommentText
+	}
+	return result
+}
+func convertDomainNotificationToModel(notification *models.Notification) *model.Notification {
+	return &model.Notification{
+		ID:        notification.ID,
+		Kind:      model.NotificationKind(notification.Kind),
+		Actor:     notification.Actor,
+		PostID:    notification.PostID,
+		CommentID: notification.CommentID,
+		CreatedAt: notification.CreatedAt,
+		Read:      notification.Read,
 	}
 }
-func convertToCommentEdges(comments []*models.Comment) []*model.CommentEdge {
+
+func convertDomainCommentsToModel(comments []*models.Comment) []*model.Comment {
+	result := make([]*model.Comment, len(comments))
+	for i, comment := range comments {
+		result[i] = convertDomainCommentToModel(comment)
+	}
+	return result
+}
+
+// convertToCommentTreeNodes converts a loaded thread. The cursor of a node
+// continues its replies after the last child that was loaded.
+func convertToCommentTreeNodes(nodes []*models.CommentNode, cursors *pagination.Codec) []*model.CommentTreeNode {
+	result := make([]*model.CommentTreeNode, len(nodes))
+	for i, node := range nodes {
+		result[i] = &model.CommentTreeNode{
+			Comment:         convertDomainCommentToModel(node.Comment),
+			Children:        convertToCommentTreeNodes(node.Children, cursors),
+			HasMoreChildren: node.HasMoreChildren,
+		}
+		if node.HasMoreChildren && len(node.Children) > 0 {
+			last := node.Children[len(node.Children)-1].Comment
+			scope := commentsScope(node.Comment.PostID, &node.Comment.ID, constants.SortAsc)
+			cursor := cursors.Encode(scope, repositories.CommentCursor(last))
+			result[i].NextCursor = &cursor
+		}
+	}
+	return result
+}
+func convertToCommentEdges(comments []*models.Comment, cursors *pagination.Codec, scope string) []*model.CommentEdge {
 	edges := make([]*model.CommentEdge, len(comments))
 	for i, comment := range comments {
 		edges[i] = &model.CommentEdge{
 			Node:   convertDomainCommentToModel(comment),
-			Cursor: comment.ID,
+			Cursor: cursors.Encode(scope, repositories.CommentCursor(comment)),
 		}
 	}
 	return edges
 }
+func convertToCommentConnection(comments []*models.Comment, page repositories.Page, hasMore bool, totalCount int, codec *pagination.Codec, scope string) *model.CommentConnection {
+	edges := convertToCommentEdges(comments, codec, scope)
+	cursors := make([]string, len(edges))
+	for i, edge := range edges {
+		cursors[i] = edge.Cursor
+	}
+
+	return &model.CommentConnection{
+		Edges:      edges,
+		PageInfo:   generatePageInfo(page, hasMore, cursors),
+		TotalCount: totalCount,
+	}
+}
+
+// levelConnection pages one level of a thread for the nested replies and
+// comments fields. First pages go through the per-request loader so that
+// sibling fields are fetched together; later pages are rare and are read
+// directly.
+func (r *Resolver) levelConnection(ctx context.Context, loader *loaders.Loader[loaders.LevelKey, *repositories.CommentPage], postID string, parentID *string, first *int, after *string, sortOrder *model.CommentSortOrder) (*model.CommentConnection, error) {
+	order := constants.SortAsc
+	if sortOrder != nil {
+		order = string(*sortOrder)
+	}
+
+	scope := commentsScope(postID, parentID, order)
+	page, err := newPage(r.cursors, scope, first, after, nil, nil, order)
+	if err != nil {
+		return nil, err
+	}
 
-func generatePageInfo(hasMore bool, comments []*models.Comment) *model.PageInfo {
-	if len(comments) == 0 {
-		return &model.PageInfo{
-			HasNextPage:     false,
-			HasPreviousPage: false,
+	if page.After != nil {
+		comments, hasMore, err := r.commentService.GetComments(postID, parentID, page)
+		if err != nil {
+			return nil, err
 		}
+		count, err := r.commentService.GetCommentsCount(postID, parentID)
+		if err != nil {
+			return nil, err
+		}
+		return convertToCommentConnection(comments, page, hasMore, count, r.cursors, scope), nil
 	}
 
-	lastID := comments[len(comments)-1].ID
-	return &model.PageInfo{
-		HasNextPage:     hasMore,
-		EndCursor:       &lastID,
-		HasPreviousPage: false,
+	levelID := postID
+	if parentID != nil {
+		levelID = *parentID
 	}
+
+	level, err := loader.Load(ctx, loaders.LevelKey{ID: levelID, Limit: page.Limit, SortOrder: order})
+	if err != nil {
+		return nil, err
+	}
+	if level == nil {
+		level = &repositories.CommentPage{}
+	}
+	return convertToCommentConnection(level.Comments, page, level.HasMore, level.TotalCount, r.cursors, scope), nil
 }
 
-func convertDomainCommentsToModelWithReplies(comments []*models.Comment, repliesMap map[string]int) []*model.Comment {
-	result := make([]*model.Comment, len(comments))
-	for i, c := range comments {
-		count := repliesMap[c.ID]
-		result[i] = &model.Comment{
-			ID:           c.ID,
-			PostID:       c.PostID,
-			ParentID:     c.ParentID,
-			Text:         c.Text,
-			Author:       c.Author,
-			CreatedAt:    c.CreatedAt,
-			RepliesCount: count,
-		}
+// postsScope, commentsScope, mentionsScope and notificationsScope name the list a cursor is issued for, so that
+// a cursor is only accepted by the list and sort order it came from.
+func postsScope(sortOrder string, filter repositories.PostFilter) string {
+	return "posts:" + sortOrder + ":" + filter.Tag + ":" + filter.Category
+}
+
+func commentsScope(postID string, parentID *string, sortOrder string) string {
+	parent := "root"
+	if parentID != nil {
+		parent = *parentID
 	}
-	return result
+	return "comments:" + postID + ":" + parent + ":" + sortOrder
+}
+
+func mentionsScope(user string) string {
+	return "mentions:" + user
+}
+
+func notificationsScope(user string, unreadOnly bool) string {
+	if unreadOnly {
+		return "notifications:" + user + ":unread"
+	}
+	return "notifications:" + user
+}
+
+// newPage builds a repository page from relay-style connection arguments.
+func newPage(cursors *pagination.Codec, scope string, first *int, after *string, last *int, before *string, sortOrder string) (repositories.Page, error) {
+	if first != nil && last != nil {
+		return repositories.Page{}, errors.New("first and last cannot be used together")
+	}
+
+	afterCursor, err := cursors.Decode(scope, after)
+	if err != nil {
+		return repositories.Page{}, err
+	}
+	beforeCursor, err := cursors.Decode(scope, before)
+	if err != nil {
+		return repositories.Page{}, err
+	}
+
+	page := repositories.Page{
+		Limit:     constants.DefaultLimit,
+		After:     afterCursor,
+		Before:    beforeCursor,
+		SortOrder: sortOrder,
+	}
+
+	switch {
+	case first != nil:
+		page.Limit = *first
+	case last != nil:
+		page.Limit = *last
+		page.Backward = true
+	}
+
+	if page.Limit < 0 {
+		return repositories.Page{}, errors.New("first and last must not be negative")
+	}
+	return page, nil
+}
+
+// generatePageInfo fills the page info of a connection. The repositories only
+// report whether more items follow in the direction of travel; for the
+// opposite direction the presence of a cursor means items exist beyond it.
+func generatePageInfo(page repositories.Page, hasMore bool, cursors []string) *model.PageInfo {
+	info := &model.PageInfo{
+		HasNextPage:     hasMore,
+		HasPreviousPage: page.After != nil,
+	}
+	if page.Backward {
+		info.HasNextPage = page.Before != nil
+		info.HasPreviousPage = hasMore
+	}
+
+	if len(cursors) > 0 {
+		startCursor := cursors[0]
+		endCursor := cursors[len(cursors)-1]
+		info.StartCursor = &startCursor
+		info.EndCursor = &endCursor
+	}
+	return info
 }
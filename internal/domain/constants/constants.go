@@ -1,11 +1,54 @@
 package constants
 
+import "time"
+
+// TimeFormat is the layout of every timestamp handed out by the service.
+// Timestamps keep microseconds, the precision PostgreSQL stores.
+const TimeFormat = time.RFC3339Nano
+
 const (
 	MaxCommentLength = 2000
 	DefaultLimit     = 10
+	DefaultTreeDepth = 3
+
+	// DefaultMaxCommentDepth is the thread depth limit used unless the
+	// server or the post configures another one.
+	DefaultMaxCommentDepth = 10
+
+	// MaxPinnedComments is how many comments a post can have pinned at once.
+	MaxPinnedComments = 3
+
+	// MaxPostTags is how many tags a post can have; a tag is at most
+	// MaxTagLength and a category at most MaxCategoryLength characters long.
+	MaxPostTags       = 10
+	MaxTagLength      = 32
+	MaxCategoryLength = 64
+
+	// DeletedCommentText is shown instead of the text of a deleted comment.
+	DeletedCommentText = "[deleted]"
+
+	// DefaultReactionEmoji is the comma-separated set of emoji comments can
+	// be reacted with unless the server configures another one.
+	DefaultReactionEmoji = "👍,👎,❤️,😂,😮,🎉"
 )
 
 const (
 	SortAsc  = "ASC"
 	SortDesc = "DESC"
+
+	// Comment-only orders: by score, by score decayed by age and by the
+	// latest reply anywhere in the subtree, best first.
+	SortTop          = "TOP"
+	SortHot          = "HOT"
+	SortLastActivity = "LAST_ACTIVITY"
+)
+
+// Notification kinds. A comment yields at most one notification per user,
+// of the first kind that applies in this order.
+const (
+	NotificationReply       = "REPLY"
+	NotificationMention     = "MENTION"
+	NotificationPostComment = "POST_COMMENT"
+	NotificationThread      = "FOLLOWED_THREAD"
+	NotificationPost        = "FOLLOWED_POST"
 )
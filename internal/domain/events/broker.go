@@ -0,0 +1,64 @@
+package events
+
+import "sync"
+
+const subscriberBuffer = 16
+
+// Broker is an in-process publish/subscribe hub keyed by topic.
+// Publishing never blocks: a subscriber that cannot keep up misses messages.
+type Broker[T any] struct {
+	mu     sync.RWMutex
+	nextID uint64
+	topics map[string]map[uint64]chan T
+}
+
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{
+		topics: make(map[string]map[uint64]chan T),
+	}
+}
+
+// Subscribe registers a new subscriber for the topic. The returned function
+// removes the subscription and closes the channel; it is safe to call more than once.
+func (b *Broker[T]) Subscribe(topic string) (<-chan T, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	ch := make(chan T, subscriberBuffer)
+
+	if _, exists := b.topics[topic]; !exists {
+		b.topics[topic] = make(map[uint64]chan T)
+	}
+	b.topics[topic][id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			subscribers := b.topics[topic]
+			delete(subscribers, id)
+			if len(subscribers) == 0 {
+				delete(b.topics, topic)
+			}
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *Broker[T]) Publish(topic string, msg T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.topics[topic] {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
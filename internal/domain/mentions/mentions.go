@@ -0,0 +1,27 @@
+package mentions
+
+import "regexp"
+
+// mention matches @name where the @ does not follow a word character, so
+// that e-mail addresses are not taken for mentions. Names are letters and
+// digits of any script plus underscores. Migration 000015 backfills existing
+// comments with a POSIX version of this pattern.
+var mention = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_]+)`)
+
+// Parse returns the users mentioned in text, each once, in order of first
+// appearance.
+func Parse(text string) []string {
+	matches := mention.FindAllStringSubmatch(text, -1)
+
+	names := make([]string, 0, len(matches))
+	seen := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		name := match[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
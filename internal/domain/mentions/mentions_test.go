@@ -0,0 +1,29 @@
+package mentions_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"posts_comments_service/internal/domain/mentions"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"no mentions here", []string{}},
+		{"@alice hi", []string{"alice"}},
+		{"thanks @bob, and @carol_2!", []string{"bob", "carol_2"}},
+		{"@bob @alice @bob", []string{"bob", "alice"}},
+		{"mail me at dave@example.com", []string{}},
+		{"(@erin) @@frank @", []string{"erin"}},
+		{"спасибо, @иван и @мария_1", []string{"иван", "мария_1"}},
+		{"почта: иван@пример.рф", []string{}},
+		{"@José, @müller.", []string{"José", "müller"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, mentions.Parse(tt.text), tt.text)
+	}
+}
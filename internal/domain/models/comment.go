@@ -8,4 +8,44 @@ type Comment struct {
 	Author       string  `json:"author"`
 	CreatedAt    string  `json:"createdAt"`
 	RepliesCount int     `json:"repliesCount"`
+	EditedAt     *string `json:"editedAt,omitempty"`
+	Deleted      bool    `json:"deleted"`
+	// Depth is 1 for top-level comments and grows by one per reply level.
+	Depth int `json:"depth"`
+	// DescendantsCount counts replies at every depth below the comment.
+	DescendantsCount int `json:"descendantsCount"`
+
+	Score     int `json:"score"`
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+	// Hot is the score decayed by age, see ranking.Hot.
+	Hot float64 `json:"hot"`
+	// LastActivityAt is the creation time of the newest comment in the
+	// subtree, the comment itself included.
+	LastActivityAt string `json:"lastActivityAt"`
+
+	// PinnedAt is set while the post author keeps the comment pinned on
+	// top of the thread.
+	PinnedAt *string `json:"pinnedAt,omitempty"`
+	// Locked forbids new replies anywhere below the comment.
+	Locked bool `json:"locked"`
+	// Mentions are the users tagged as @name in the text.
+	Mentions []string `json:"mentions"`
+}
+
+// CommentNode is a comment together with the replies loaded under it.
+// HasMoreChildren reports replies that were cut off by the depth or
+// per-node limit of the query.
+type CommentNode struct {
+	Comment         *Comment
+	Children        []*CommentNode
+	HasMoreChildren bool
+}
+
+// CommentRevision is a previous version of a comment's text.
+// CreatedAt is the time that version was written.
+type CommentRevision struct {
+	CommentID string `json:"commentId"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"createdAt"`
 }
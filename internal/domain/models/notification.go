@@ -0,0 +1,14 @@
+package models
+
+// Notification tells Recipient that Actor wrote a comment concerning them;
+// Kind is one of the constants.Notification* kinds.
+type Notification struct {
+	ID        string `json:"id"`
+	Recipient string `json:"recipient"`
+	Kind      string `json:"kind"`
+	Actor     string `json:"actor"`
+	PostID    string `json:"postId"`
+	CommentID string `json:"commentId"`
+	CreatedAt string `json:"createdAt"`
+	Read      bool   `json:"read"`
+}
@@ -7,4 +7,26 @@ type Post struct {
 	Author        string `json:"author"`
 	AllowComments bool   `json:"allowComments"`
 	CreatedAt     string `json:"createdAt"`
+
+	CommentsClosedBy *string `json:"commentsClosedBy,omitempty"`
+	CommentsClosedAt *string `json:"commentsClosedAt,omitempty"`
+
+	// MaxCommentDepth overrides the server-wide thread depth limit;
+	// nil uses the server default and 0 allows unlimited nesting.
+	MaxCommentDepth *int `json:"maxCommentDepth,omitempty"`
+
+	// CommentsTotal counts the comments of the post at every depth.
+	CommentsTotal int `json:"commentsTotal"`
+
+	Score     int `json:"score"`
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+
+	// ArchivedAt is set once the post is archived for age; an archived post
+	// is read-only.
+	ArchivedAt *string `json:"archivedAt,omitempty"`
+
+	// Tags are lowercase and sorted; Category is nil for uncategorized posts.
+	Tags     []string `json:"tags"`
+	Category *string  `json:"category,omitempty"`
 }
@@ -0,0 +1,9 @@
+package models
+
+// ReactionSummary counts the reactions with one emoji on a comment.
+// ReactedByMe reports whether the requesting user is among them.
+type ReactionSummary struct {
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reactedByMe"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// TagCount is a tag and the number of posts tagged with it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// VoteTally is the vote state of a post or comment right after a vote.
+// Value is the vote of the voter who cast it: -1, 0 (none) or 1.
+type VoteTally struct {
+	TargetID  string
+	Score     int
+	Upvotes   int
+	Downvotes int
+	Value     int
+}
@@ -0,0 +1,100 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"posts_comments_service/internal/domain/repositories"
+)
+
+// Codec turns keyset cursors into opaque tokens and back. A token carries
+// the scope it was issued for (list, parent and sort order) and is signed
+// with the server key, so it cannot be forged or replayed in another list.
+type Codec struct {
+	key []byte
+}
+
+type payload struct {
+	Scope     string    `json:"s"`
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"i"`
+
+	Score          int        `json:"sc,omitempty"`
+	Hot            float64    `json:"h,omitempty"`
+	LastActivityAt *time.Time `json:"a,omitempty"`
+	PinnedAt       *time.Time `json:"p,omitempty"`
+}
+
+func NewCodec(key []byte) *Codec {
+	return &Codec{key: key}
+}
+
+func (c *Codec) Encode(scope string, cursor *repositories.Cursor) string {
+	p := payload{
+		Scope:     scope,
+		CreatedAt: cursor.CreatedAt,
+		ID:        cursor.ID,
+		Score:     cursor.Score,
+		Hot:       cursor.Hot,
+	}
+	if !cursor.LastActivityAt.IsZero() {
+		p.LastActivityAt = &cursor.LastActivityAt
+	}
+	if cursor.Pinned {
+		p.PinnedAt = &cursor.PinnedAt
+	}
+	data, _ := json.Marshal(p)
+
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(c.sign(data))
+}
+
+// Decode verifies the token and returns its cursor. A nil token yields a nil cursor.
+func (c *Codec) Decode(scope string, token *string) (*repositories.Cursor, error) {
+	if token == nil {
+		return nil, nil
+	}
+
+	encodedData, encodedSignature, found := strings.Cut(*token, ".")
+	if !found {
+		return nil, repositories.ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encodedData)
+	if err != nil {
+		return nil, repositories.ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(data)) {
+		return nil, repositories.ErrInvalidCursor
+	}
+
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil || p.Scope != scope {
+		return nil, repositories.ErrInvalidCursor
+	}
+
+	cursor := &repositories.Cursor{
+		CreatedAt: p.CreatedAt,
+		ID:        p.ID,
+		Score:     p.Score,
+		Hot:       p.Hot,
+	}
+	if p.LastActivityAt != nil {
+		cursor.LastActivityAt = *p.LastActivityAt
+	}
+	if p.PinnedAt != nil {
+		cursor.Pinned = true
+		cursor.PinnedAt = *p.PinnedAt
+	}
+	return cursor, nil
+}
+
+func (c *Codec) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,95 @@
+package pagination_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/pagination"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := pagination.NewCodec([]byte("secret"))
+	cursor := &repositories.Cursor{
+		CreatedAt: time.Date(2025, 7, 11, 5, 0, 21, 123456000, time.UTC),
+		ID:        "36a4bba7-6b25-4936-8ca6-9127a90a9565",
+	}
+
+	token := codec.Encode("comments:post:root:ASC", cursor)
+	assert.NotContains(t, token, cursor.ID)
+
+	decoded, err := codec.Decode("comments:post:root:ASC", &token)
+	require.NoError(t, err)
+	assert.Equal(t, cursor.ID, decoded.ID)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+
+	decoded, err = codec.Decode("comments:post:root:ASC", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+}
+
+func TestCodec_RejectsForeignCursors(t *testing.T) {
+	codec := pagination.NewCodec([]byte("secret"))
+	token := codec.Encode("comments:post:root:ASC", &repositories.Cursor{ID: "id"})
+
+	_, err := codec.Decode("comments:post:root:DESC", &token)
+	assert.ErrorIs(t, err, repositories.ErrInvalidCursor)
+
+	_, err = codec.Decode("comments:post:parent:ASC", &token)
+	assert.ErrorIs(t, err, repositories.ErrInvalidCursor)
+
+	_, err = pagination.NewCodec([]byte("other")).Decode("comments:post:root:ASC", &token)
+	assert.ErrorIs(t, err, repositories.ErrInvalidCursor)
+
+	data, signature, _ := strings.Cut(token, ".")
+	tampered := data[:len(data)-2] + "x" + data[len(data)-1:] + "." + signature
+	_, err = codec.Decode("comments:post:root:ASC", &tampered)
+	assert.ErrorIs(t, err, repositories.ErrInvalidCursor)
+
+	raw := "36a4bba7-6b25-4936-8ca6-9127a90a9565"
+	_, err = codec.Decode("comments:post:root:ASC", &raw)
+	assert.ErrorIs(t, err, repositories.ErrInvalidCursor)
+}
+
+func TestCodec_RankedSortKeys(t *testing.T) {
+	codec := pagination.NewCodec([]byte("secret"))
+	cursor := &repositories.Cursor{
+		CreatedAt:      time.Date(2025, 7, 11, 5, 0, 21, 123456000, time.UTC),
+		ID:             "36a4bba7-6b25-4936-8ca6-9127a90a9565",
+		Score:          -3,
+		Hot:            9912.345678901234,
+		LastActivityAt: time.Date(2025, 7, 12, 8, 30, 0, 5000, time.UTC),
+	}
+
+	token := codec.Encode("comments:post:root:HOT", cursor)
+	decoded, err := codec.Decode("comments:post:root:HOT", &token)
+	require.NoError(t, err)
+	assert.Equal(t, cursor.Score, decoded.Score)
+	assert.Equal(t, cursor.Hot, decoded.Hot)
+	assert.True(t, cursor.LastActivityAt.Equal(decoded.LastActivityAt))
+}
+
+func TestCodec_PinnedKey(t *testing.T) {
+	codec := pagination.NewCodec([]byte("secret"))
+	cursor := &repositories.Cursor{
+		CreatedAt: time.Date(2025, 7, 11, 5, 0, 21, 0, time.UTC),
+		ID:        "36a4bba7-6b25-4936-8ca6-9127a90a9565",
+		Pinned:    true,
+		PinnedAt:  time.Date(2025, 7, 13, 9, 0, 0, 1000, time.UTC),
+	}
+
+	token := codec.Encode("comments:post:root:ASC", cursor)
+	decoded, err := codec.Decode("comments:post:root:ASC", &token)
+	require.NoError(t, err)
+	assert.True(t, decoded.Pinned)
+	assert.True(t, cursor.PinnedAt.Equal(decoded.PinnedAt))
+
+	cursor.Pinned = false
+	token = codec.Encode("comments:post:root:ASC", cursor)
+	decoded, err = codec.Decode("comments:post:root:ASC", &token)
+	require.NoError(t, err)
+	assert.False(t, decoded.Pinned)
+}
@@ -0,0 +1,31 @@
+package ranking
+
+import (
+	"math"
+	"time"
+)
+
+// epoch and decay follow the "hot" ranking popularised by Reddit: every
+// 45000 seconds of age weigh as much as a tenfold difference in score.
+const (
+	epoch = 1134028003
+	decay = 45000
+)
+
+// Hot ranks an item by its score decayed by age. The rank depends only on
+// the score and creation time, never on the current time, so it can be
+// stored and only needs updating when the score changes.
+func Hot(score int, createdAt time.Time) float64 {
+	order := math.Log10(math.Max(math.Abs(float64(score)), 1))
+
+	sign := 0.0
+	switch {
+	case score > 0:
+		sign = 1
+	case score < 0:
+		sign = -1
+	}
+
+	seconds := float64(createdAt.Unix()-epoch) + float64(createdAt.Nanosecond())/1e9
+	return sign*order + seconds/decay
+}
@@ -0,0 +1,20 @@
+package ranking_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"posts_comments_service/internal/domain/ranking"
+)
+
+func TestHot(t *testing.T) {
+	createdAt := time.Date(2025, 7, 11, 5, 0, 0, 0, time.UTC)
+
+	assert.Greater(t, ranking.Hot(10, createdAt), ranking.Hot(1, createdAt))
+	assert.Greater(t, ranking.Hot(0, createdAt), ranking.Hot(-10, createdAt))
+	assert.Greater(t, ranking.Hot(0, createdAt.Add(time.Hour)), ranking.Hot(0, createdAt))
+
+	// 12.5 hours of age weigh as much as a tenfold score.
+	assert.InDelta(t, ranking.Hot(100, createdAt), ranking.Hot(10, createdAt.Add(45000*time.Second)), 1e-9)
+}
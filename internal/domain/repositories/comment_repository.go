@@ -4,7 +4,42 @@ import "posts_comments_service/internal/domain/models"
 
 type CommentRepository interface {
 	Create(comment *models.Comment) error
-	GetByPostID(postID string, parentID *string, limit int, after *string, sortOrder string) ([]*models.Comment, bool, error)
+	GetByID(id string) (*models.Comment, error)
+	// GetAncestors returns the chain of parents of a comment, top-level comment first.
+	GetAncestors(id string) ([]*models.Comment, error)
+	GetByPostID(postID string, parentID *string, page Page) ([]*models.Comment, bool, error)
 	Count(postID string, parentID *string) (int, error)
-	CountReplies(postID string) (map[string]int, error)
+	// CountDescendants counts the replies of a comment at every depth,
+	// CountAll the comments of a post at every depth.
+	CountDescendants(id string) (int, error)
+	CountAll(postID string) (int, error)
+	// GetRepliesPages and GetTopLevelPages fetch the first page of many levels
+	// in one go, keyed by parent comment ID and by post ID respectively.
+	GetRepliesPages(parentIDs []string, limit int, sortOrder string) (map[string]*CommentPage, error)
+	GetTopLevelPages(postIDs []string, limit int, sortOrder string) (map[string]*CommentPage, error)
+	// GetTree loads up to maxDepth levels below the top-level comments of the
+	// post, or below and including rootID, keeping at most maxChildren replies
+	// per node in creation order, pinned roots first. The flag reports more
+	// roots beyond the limit.
+	GetTree(postID string, rootID *string, maxDepth, maxChildren int) ([]*models.CommentNode, bool, error)
+	DeleteByPostID(postID string) error
+	// Edit replaces the text and the mentions parsed from it.
+	Edit(id string, text string, mentions []string, editedAt string) (*models.Comment, error)
+	GetRevisions(commentID string) ([]*models.CommentRevision, error)
+	Delete(id string) error
+	// Pin puts a top-level comment on top of its post unless maxPinned
+	// comments are pinned there already; pinning a pinned comment changes
+	// nothing. Unpin takes it back to its place in the thread.
+	Pin(id string, pinnedAt string, maxPinned int) (*models.Comment, error)
+	Unpin(id string) (*models.Comment, error)
+	SetLocked(id string, locked bool) (*models.Comment, error)
+	// GetMentioning pages the comments that mention the user, newest first,
+	// across all posts. CountMentioning counts them.
+	GetMentioning(user string, page Page) ([]*models.Comment, bool, error)
+	CountMentioning(user string) (int, error)
+	// Vote records the vote of the voter, replacing an earlier one; 0
+	// withdraws it. Counters, score and hot rank are adjusted atomically.
+	Vote(id string, voter string, value int) (*models.VoteTally, error)
+	// GetVotes returns the votes of the voter on the comments that have one.
+	GetVotes(voter string, ids []string) (map[string]int, error)
 }
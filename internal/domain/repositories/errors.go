@@ -8,4 +8,10 @@ var (
 	ErrCommentsDisabled = errors.New("comments are disabled for this post")
 	ErrTextTooLong      = errors.New("comment text exceeds the 2000 character limit")
 	ErrParentNotFound   = errors.New("parent comment not found")
+	ErrCommentDeleted   = errors.New("comment has been deleted")
+	ErrMaxDepthExceeded = errors.New("maximum thread depth exceeded")
+	ErrNotPostAuthor    = errors.New("only the post author can do this")
+	ErrPinLimitReached  = errors.New("pinned comments limit reached")
+	ErrPostArchived     = errors.New("post is archived")
+	ErrThreadLocked     = errors.New("thread is locked")
 )
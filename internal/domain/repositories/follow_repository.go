@@ -0,0 +1,22 @@
+package repositories
+
+// FollowRepository stores who follows a post or a comment thread. Following
+// twice or unfollowing without following is not an error.
+type FollowRepository interface {
+	FollowPost(postID, user string) error
+	UnfollowPost(postID, user string) error
+	FollowThread(commentID, user string) error
+	UnfollowThread(commentID, user string) error
+
+	// CountPostFollowers returns the number of followers of each post.
+	// Posts without followers are missing from the map.
+	CountPostFollowers(postIDs []string) (map[string]int, error)
+	// GetFollowedPosts reports which of the posts the user follows.
+	GetFollowedPosts(user string, postIDs []string) (map[string]bool, error)
+
+	// GetPostFollowers returns the followers of a post, sorted by name.
+	GetPostFollowers(postID string) ([]string, error)
+	// GetThreadFollowers returns the users following any of the comments,
+	// each once, sorted by name.
+	GetThreadFollowers(commentIDs []string) ([]string, error)
+}
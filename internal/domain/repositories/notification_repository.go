@@ -0,0 +1,13 @@
+package repositories
+
+import "posts_comments_service/internal/domain/models"
+
+type NotificationRepository interface {
+	Create(notifications []*models.Notification) error
+	// List pages the notifications of the recipient, newest first.
+	List(recipient string, unreadOnly bool, page Page) ([]*models.Notification, bool, error)
+	CountUnread(recipient string) (int, error)
+	// MarkRead marks the listed notifications of the recipient as read and
+	// returns how many were unread; ids of others are ignored.
+	MarkRead(recipient string, ids []string) (int, error)
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"slices"
+	"time"
+
+	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/models"
+)
+
+// Page selects a window of a keyset-ordered list. The window lies strictly
+// between the After and Before cursors when they are set. A forward page
+// takes the first Limit items of that window, a Backward one takes the last
+// Limit items; either way items are returned in SortOrder.
+type Page struct {
+	Limit     int
+	After     *Cursor
+	Before    *Cursor
+	Backward  bool
+	SortOrder string
+}
+
+// CommentPage is the first page of one level of a comment thread together
+// with the number of comments on that level.
+type CommentPage struct {
+	Comments   []*models.Comment
+	HasMore    bool
+	TotalCount int
+}
+
+// Cursor is the keyset position of a row: its sort key and id. Comment
+// cursors also carry the keys of the ranked orders; which fields are
+// compared depends on the sort order of the page.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+
+	Score          int
+	Hot            float64
+	LastActivityAt time.Time
+
+	// Pinned cursors point into the pinned comments listed ahead of the
+	// rest of a level, ordered by PinnedAt and id.
+	Pinned   bool
+	PinnedAt time.Time
+}
+
+func CommentCursor(comment *models.Comment) *Cursor {
+	cursor := newCursor(comment.CreatedAt, comment.ID)
+	cursor.Score = comment.Score
+	cursor.Hot = comment.Hot
+	cursor.LastActivityAt, _ = time.Parse(constants.TimeFormat, comment.LastActivityAt)
+	if comment.PinnedAt != nil {
+		cursor.Pinned = true
+		cursor.PinnedAt, _ = time.Parse(constants.TimeFormat, *comment.PinnedAt)
+	}
+	return cursor
+}
+
+// PinnedFirst pages a level listed as its pinned comments followed by the
+// rest in the sort order of the page. fetch pages one of the two segments;
+// it is handed only the cursors that fall inside that segment.
+func PinnedFirst(page Page, fetch func(pinned bool, page Page) ([]*models.Comment, bool, error)) ([]*models.Comment, bool, error) {
+	if page.Limit <= 0 {
+		return []*models.Comment{}, false, nil
+	}
+
+	// A segment is skipped when the window lies entirely on the other side of it.
+	segments := make([]bool, 0, 2)
+	if page.After == nil || page.After.Pinned {
+		segments = append(segments, true)
+	}
+	if page.Before == nil || !page.Before.Pinned {
+		segments = append(segments, false)
+	}
+	if page.Backward {
+		slices.Reverse(segments)
+	}
+
+	var result []*models.Comment
+	for i, pinned := range segments {
+		segment := page
+		segment.Limit = page.Limit - len(result)
+		if page.After != nil && page.After.Pinned != pinned {
+			segment.After = nil
+		}
+		if page.Before != nil && page.Before.Pinned != pinned {
+			segment.Before = nil
+		}
+
+		// A full page still has to know whether the next segment is empty.
+		if segment.Limit == 0 {
+			segment.Limit = 1
+			probe, _, err := fetch(pinned, segment)
+			return result, len(probe) > 0, err
+		}
+
+		comments, hasMore, err := fetch(pinned, segment)
+		if err != nil {
+			return nil, false, err
+		}
+		if page.Backward {
+			result = slices.Concat(comments, result)
+		} else {
+			result = append(result, comments...)
+		}
+		if hasMore || i == len(segments)-1 {
+			return result, hasMore, nil
+		}
+	}
+	return result, false, nil
+}
+
+func PostCursor(post *models.Post) *Cursor {
+	return newCursor(post.CreatedAt, post.ID)
+}
+
+func NotificationCursor(notification *models.Notification) *Cursor {
+	return newCursor(notification.CreatedAt, notification.ID)
+}
+
+func newCursor(createdAt, id string) *Cursor {
+	t, _ := time.Parse(constants.TimeFormat, createdAt)
+	return &Cursor{CreatedAt: t, ID: id}
+}
@@ -2,8 +2,33 @@ package repositories
 
 import "posts_comments_service/internal/domain/models"
 
+// PostFilter narrows a list of posts to those with the tag and in the
+// category; empty fields match every post.
+type PostFilter struct {
+	Tag      string
+	Category string
+}
+
 type PostRepository interface {
 	Create(post *models.Post) error
 	GetByID(id string) (*models.Post, error)
-	List(limit int, after *string, sortOrder string) ([]*models.Post, error)
+	// GetByIDs returns the posts with the ids; missing posts are missing
+	// from the map.
+	GetByIDs(ids []string) (map[string]*models.Post, error)
+	List(filter PostFilter, page Page) ([]*models.Post, bool, error)
+	Count(filter PostFilter) (int, error)
+	Update(post *models.Post) error
+	Delete(id string) error
+	SetCommentsEnabled(id string, enabled bool, closedBy *string, closedAt *string) error
+	SetMaxCommentDepth(id string, maxDepth *int) error
+	// Archive marks the posts created before createdBefore as archived at
+	// archivedAt and returns how many were archived; archived posts are skipped.
+	Archive(createdBefore, archivedAt string) (int, error)
+	Vote(id string, voter string, value int) (*models.VoteTally, error)
+	GetVotes(voter string, ids []string) (map[string]int, error)
+	SetTags(id string, tags []string) error
+	SetCategory(id string, category *string) error
+	// ListTags returns the tags starting with prefix and how many posts use
+	// them, most used first.
+	ListTags(prefix string, limit int) ([]*models.TagCount, error)
 }
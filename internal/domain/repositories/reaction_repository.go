@@ -0,0 +1,14 @@
+package repositories
+
+import "posts_comments_service/internal/domain/models"
+
+// ReactionRepository stores emoji reactions on comments. A user reacts at
+// most once with each emoji; adding an existing reaction or removing a
+// missing one is not an error.
+type ReactionRepository interface {
+	Add(commentID, emoji, user string) error
+	Remove(commentID, emoji, user string) error
+	// GetSummaries returns the reactions of each comment, most used emoji
+	// first. Comments without reactions are missing from the map.
+	GetSummaries(commentIDs []string, user string) (map[string][]*models.ReactionSummary, error)
+}
@@ -1,22 +1,49 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"github.com/google/uuid"
 	"posts_comments_service/internal/domain/constants"
-	"time"
+	"posts_comments_service/internal/domain/events"
+	"posts_comments_service/internal/domain/mentions"
+	"posts_comments_service/internal/domain/ranking"
+	"slices"
 
 	"posts_comments_service/internal/domain/models"
 	"posts_comments_service/internal/domain/repositories"
 )
 
+// ThreadLimits configures how deep comment threads may grow.
+type ThreadLimits struct {
+	// MaxDepth applies to posts without a limit of their own; 0 means unlimited.
+	MaxDepth int
+	// FlattenDeepReplies attaches replies that would exceed the limit to the
+	// deepest allowed ancestor instead of rejecting them.
+	FlattenDeepReplies bool
+}
+
+// CommentObserver is told about every comment added through the service.
+// It runs synchronously after the comment is stored and cannot fail the
+// request.
+type CommentObserver interface {
+	CommentAdded(post *models.Post, comment *models.Comment)
+}
+
 type CommentService struct {
-	repo repositories.CommentRepository
+	repo      repositories.CommentRepository
+	postRepo  repositories.PostRepository
+	limits    ThreadLimits
+	broker    *events.Broker[*models.Comment]
+	observers []CommentObserver
 }
 
-func NewCommentService(repo repositories.CommentRepository) *CommentService {
+func NewCommentService(repo repositories.CommentRepository, postRepo repositories.PostRepository, limits ThreadLimits) *CommentService {
 	return &CommentService{
-		repo: repo,
+		repo:     repo,
+		postRepo: postRepo,
+		limits:   limits,
+		broker:   events.NewBroker[*models.Comment](),
 	}
 }
 
@@ -25,30 +52,293 @@ func (s *CommentService) AddComment(postID, author, text string, parentID *strin
 		return nil, errors.New("comment text exceeds the 2000 character limit")
 	}
 
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureWritable(post); err != nil {
+		return nil, err
+	}
+	if !post.AllowComments {
+		return nil, repositories.ErrCommentsDisabled
+	}
+
+	if parentID != nil {
+		replyTo, err := s.replyParent(post, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		parentID = &replyTo
+	}
+
+	createdAt := currentTime()
 	comment := &models.Comment{
-		ID:        uuid.New().String(),
-		PostID:    postID,
-		ParentID:  parentID,
-		Author:    author,
-		Text:      text,
-		CreatedAt: time.Now().Format(time.RFC3339),
+		ID:             uuid.New().String(),
+		PostID:         postID,
+		ParentID:       parentID,
+		Author:         author,
+		Text:           text,
+		CreatedAt:      createdAt.Format(constants.TimeFormat),
+		Hot:            ranking.Hot(0, createdAt),
+		LastActivityAt: createdAt.Format(constants.TimeFormat),
+		Mentions:       mentions.Parse(text),
 	}
 
 	if err := s.repo.Create(comment); err != nil {
 		return nil, err
 	}
 
+	for _, observer := range s.observers {
+		observer.CommentAdded(post, comment)
+	}
+	s.broker.Publish(comment.PostID, comment)
+
+	return comment, nil
+}
+
+// Observe registers an observer of new comments. It is meant to be called
+// while wiring the service, before it serves requests.
+func (s *CommentService) Observe(observer CommentObserver) {
+	s.observers = append(s.observers, observer)
+}
+
+// replyParent returns the comment a reply to parentID is attached to. A reply
+// below a locked comment is rejected with ErrThreadLocked. A reply that would
+// be nested deeper than the limit of the post is rejected with
+// ErrMaxDepthExceeded, or moved up to the deepest allowed ancestor when
+// flattening is on.
+func (s *CommentService) replyParent(post *models.Post, parentID string) (string, error) {
+	parent, err := s.repo.GetByID(parentID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return "", repositories.ErrParentNotFound
+		}
+		return "", err
+	}
+	if parent.PostID != post.ID {
+		return "", repositories.ErrParentNotFound
+	}
+
+	ancestors, err := s.repo.GetAncestors(parentID)
+	if err != nil {
+		return "", err
+	}
+	isLocked := func(c *models.Comment) bool { return c.Locked }
+	if parent.Locked || slices.ContainsFunc(ancestors, isLocked) {
+		return "", repositories.ErrThreadLocked
+	}
+
+	limit := s.limits.MaxDepth
+	if post.MaxCommentDepth != nil {
+		limit = *post.MaxCommentDepth
+	}
+
+	if limit == 0 || parent.Depth < limit {
+		return parentID, nil
+	}
+	if !s.limits.FlattenDeepReplies || limit < 2 {
+		return "", repositories.ErrMaxDepthExceeded
+	}
+	// ancestors[i] has depth i+1; the reply lands one level below limit-1.
+	return ancestors[limit-2].ID, nil
+}
+
+// SubscribeComments streams comments added to the post until ctx is done.
+// With a nil parentID every new comment of the post is delivered, otherwise
+// only direct replies to that comment.
+func (s *CommentService) SubscribeComments(ctx context.Context, postID string, parentID *string) <-chan *models.Comment {
+	source, unsubscribe := s.broker.Subscribe(postID)
+	out := make(chan *models.Comment, 1)
+
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case comment, ok := <-source:
+				if !ok {
+					return
+				}
+				if parentID != nil && (comment.ParentID == nil || *comment.ParentID != *parentID) {
+					continue
+				}
+				select {
+				case out <- comment:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// EditComment replaces the comment text; the previous version is kept as a revision.
+func (s *CommentService) EditComment(id, text string) (*models.Comment, error) {
+	if len(text) > constants.MaxCommentLength {
+		return nil, repositories.ErrTextTooLong
+	}
+
+	if _, _, err := s.writableComment(id); err != nil {
+		return nil, err
+	}
+	return s.repo.Edit(id, text, mentions.Parse(text), now())
+}
+
+// DeleteComment tombstones a comment: its text, author and revisions are
+// erased, but it stays in the thread so that replies keep their place.
+func (s *CommentService) DeleteComment(id string) error {
+	if _, _, err := s.writableComment(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// PinComment keeps a top-level comment on top of the thread. Only the
+// author of the post can pin, up to constants.MaxPinnedComments comments.
+func (s *CommentService) PinComment(id, user string) (*models.Comment, error) {
+	comment, err := s.authorizeModeration(id, user)
+	if err != nil {
+		return nil, err
+	}
+	if comment.ParentID != nil {
+		return nil, errors.New("only top-level comments can be pinned")
+	}
+	if comment.Deleted {
+		return nil, repositories.ErrCommentDeleted
+	}
+
+	return s.repo.Pin(id, now(), constants.MaxPinnedComments)
+}
+
+func (s *CommentService) UnpinComment(id, user string) (*models.Comment, error) {
+	if _, err := s.authorizeModeration(id, user); err != nil {
+		return nil, err
+	}
+	return s.repo.Unpin(id)
+}
+
+// SetThreadLocked locks or unlocks the subtree of a comment. A locked
+// subtree takes no new replies; existing ones stay as they are. Only the
+// author of the post can lock.
+func (s *CommentService) SetThreadLocked(id string, locked bool, user string) (*models.Comment, error) {
+	if _, err := s.authorizeModeration(id, user); err != nil {
+		return nil, err
+	}
+	return s.repo.SetLocked(id, locked)
+}
+
+// writableComment loads a comment and its post, which must not be archived.
+func (s *CommentService) writableComment(id string) (*models.Comment, *models.Post, error) {
+	comment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	post, err := s.postRepo.GetByID(comment.PostID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ensureWritable(post); err != nil {
+		return nil, nil, err
+	}
+	return comment, post, nil
+}
+
+// authorizeModeration loads the comment and checks that user wrote its post.
+func (s *CommentService) authorizeModeration(id, user string) (*models.Comment, error) {
+	comment, post, err := s.writableComment(id)
+	if err != nil {
+		return nil, err
+	}
+	if post.Author != user {
+		return nil, repositories.ErrNotPostAuthor
+	}
 	return comment, nil
 }
 
-func (s *CommentService) GetComments(postID string, parentID *string, limit int, after *string, sortOrder string) ([]*models.Comment, bool, error) {
-	return s.repo.GetByPostID(postID, parentID, limit, after, sortOrder)
+func (s *CommentService) GetComment(id string) (*models.Comment, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetAncestors returns the path from the top-level comment down to the
+// parent of the given comment.
+func (s *CommentService) GetAncestors(id string) ([]*models.Comment, error) {
+	return s.repo.GetAncestors(id)
+}
+
+func (s *CommentService) GetRevisions(commentID string) ([]*models.CommentRevision, error) {
+	return s.repo.GetRevisions(commentID)
+}
+
+func (s *CommentService) GetComments(postID string, parentID *string, page repositories.Page) ([]*models.Comment, bool, error) {
+	if !validCommentSortOrder(page.SortOrder) {
+		return nil, false, errors.New("invalid sort order")
+	}
+	return s.repo.GetByPostID(postID, parentID, page)
+}
+
+// GetRepliesPages returns the first page of replies for each of the comments.
+func (s *CommentService) GetRepliesPages(parentIDs []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error) {
+	if !validCommentSortOrder(sortOrder) {
+		return nil, errors.New("invalid sort order")
+	}
+	return s.repo.GetRepliesPages(parentIDs, limit, sortOrder)
+}
+
+// GetTopLevelPages returns the first page of top-level comments for each of the posts.
+func (s *CommentService) GetTopLevelPages(postIDs []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error) {
+	if !validCommentSortOrder(sortOrder) {
+		return nil, errors.New("invalid sort order")
+	}
+	return s.repo.GetTopLevelPages(postIDs, limit, sortOrder)
+}
+
+func validCommentSortOrder(sortOrder string) bool {
+	switch sortOrder {
+	case constants.SortAsc, constants.SortDesc, constants.SortTop, constants.SortHot, constants.SortLastActivity:
+		return true
+	}
+	return false
+}
+
+// GetCommentTree returns a nested thread limited in depth and in the number
+// of replies per node, so a discussion can be rendered with a single call.
+func (s *CommentService) GetCommentTree(postID string, rootID *string, maxDepth, maxChildren int) ([]*models.CommentNode, bool, error) {
+	if maxDepth < 1 || maxChildren < 1 {
+		return nil, false, errors.New("maxDepth and maxChildrenPerNode must be positive")
+	}
+	return s.repo.GetTree(postID, rootID, maxDepth, maxChildren)
+}
+
+// GetMentions pages the comments that mention the user, newest first.
+func (s *CommentService) GetMentions(user string, page repositories.Page) ([]*models.Comment, bool, error) {
+	page.SortOrder = constants.SortDesc
+	return s.repo.GetMentioning(user, page)
+}
+
+func (s *CommentService) GetMentionsCount(user string) (int, error) {
+	return s.repo.CountMentioning(user)
 }
 
 func (s *CommentService) GetCommentsCount(postID string, parentID *string) (int, error) {
 	return s.repo.Count(postID, parentID)
 }
 
-func (s *CommentService) GetRepliesCounts(postID string) (map[string]int, error) {
-	return s.repo.CountReplies(postID)
+// GetSubtreeCount counts comments at every depth: all comments of the post,
+// or all replies below the parent when one is given.
+func (s *CommentService) GetSubtreeCount(postID string, parentID *string) (int, error) {
+	if parentID == nil {
+		return s.repo.CountAll(postID)
+	}
+
+	parent, err := s.repo.GetByID(*parentID)
+	if err != nil || parent.PostID != postID {
+		return 0, repositories.ErrParentNotFound
+	}
+	return s.repo.CountDescendants(*parentID)
 }
@@ -1,10 +1,13 @@
 package services_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/models"
 	"posts_comments_service/internal/domain/repositories"
 	"posts_comments_service/internal/domain/services"
 	"posts_comments_service/internal/repository/memory"
@@ -14,8 +17,8 @@ func TestAddComment_Success(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
 	require.NoError(t, err)
@@ -33,8 +36,8 @@ func TestAddComment_TextTooLong(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
 	require.NoError(t, err)
@@ -53,8 +56,8 @@ func TestAddComment_DisabledComments(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("No Comments", "Content", "Author", false)
 	require.NoError(t, err)
@@ -67,8 +70,8 @@ func TestAddComment_ParentNotFound(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
 	require.NoError(t, err)
@@ -82,18 +85,19 @@ func TestGetComments(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
 	require.NoError(t, err)
 
 	_, err = commentService.AddComment(post.ID, "User1", "First comment", nil)
 	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
 	_, err = commentService.AddComment(post.ID, "User2", "Second comment", nil)
 	require.NoError(t, err)
 
-	comments, hasMore, err := commentService.GetComments(post.ID, nil, 10, nil, "ASC")
+	comments, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: "ASC"})
 
 	require.NoError(t, err)
 	assert.Len(t, comments, 2)
@@ -106,25 +110,27 @@ func TestGetComments_WithPagination(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
 	require.NoError(t, err)
 
 	_, err = commentService.AddComment(post.ID, "User1", "Comment 1", nil)
 	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
 	_, err = commentService.AddComment(post.ID, "User2", "Comment 2", nil)
 	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
 	_, err = commentService.AddComment(post.ID, "User3", "Comment 3", nil)
 	require.NoError(t, err)
 
-	comments, hasMore, err := commentService.GetComments(post.ID, nil, 2, nil, "ASC")
+	comments, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, SortOrder: "ASC"})
 	require.NoError(t, err)
 	assert.Len(t, comments, 2)
 	assert.True(t, hasMore)
 
-	nextComments, hasMore, err := commentService.GetComments(post.ID, nil, 2, &comments[1].ID, "ASC")
+	nextComments, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, After: repositories.CommentCursor(comments[1]), SortOrder: "ASC"})
 	require.NoError(t, err)
 	assert.Len(t, nextComments, 1)
 	assert.False(t, hasMore)
@@ -134,8 +140,8 @@ func TestAddNestedComment(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
 	require.NoError(t, err)
@@ -146,7 +152,7 @@ func TestAddNestedComment(t *testing.T) {
 	child, err := commentService.AddComment(post.ID, "Child", "Child comment", &parent.ID)
 	require.NoError(t, err)
 
-	replies, hasMore, err := commentService.GetComments(post.ID, &parent.ID, 10, nil, "ASC")
+	replies, hasMore, err := commentService.GetComments(post.ID, &parent.ID, repositories.Page{Limit: 10, SortOrder: "ASC"})
 
 	require.NoError(t, err)
 	assert.False(t, hasMore)
@@ -159,8 +165,8 @@ func TestGetCommentsCount(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
 	require.NoError(t, err)
@@ -179,8 +185,8 @@ func TestNestedComments(t *testing.T) {
 	postRepo := memory.NewPostRepository()
 	commentRepo := memory.NewCommentRepository(postRepo)
 
-	postService := services.NewPostService(postRepo)
-	commentService := services.NewCommentService(commentRepo)
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
 
 	post, err := postService.CreatePost("Nested Comments Test", "Content", "author1", true)
 	require.NoError(t, err)
@@ -189,12 +195,14 @@ func TestNestedComments(t *testing.T) {
 	rootComment1, err := commentService.AddComment(post.ID, "user1", "Root comment 1", nil)
 	require.NoError(t, err)
 
+	time.Sleep(time.Millisecond)
 	rootComment2, err := commentService.AddComment(post.ID, "user2", "Root comment 2", nil)
 	require.NoError(t, err)
 
 	child1OfRoot1, err := commentService.AddComment(post.ID, "user3", "Child 1 of Root 1", &rootComment1.ID)
 	require.NoError(t, err)
 
+	time.Sleep(time.Millisecond)
 	child2OfRoot1, err := commentService.AddComment(post.ID, "user1", "Child 2 of Root 1", &rootComment1.ID)
 	require.NoError(t, err)
 
@@ -204,24 +212,25 @@ func TestNestedComments(t *testing.T) {
 	grandchild1, err := commentService.AddComment(post.ID, "user2", "Grandchild 1", &child1OfRoot1.ID)
 	require.NoError(t, err)
 
+	time.Sleep(time.Millisecond)
 	grandchild2, err := commentService.AddComment(post.ID, "user3", "Grandchild 2", &child1OfRoot1.ID)
 	require.NoError(t, err)
 
-	rootComments, hasMore, err := commentService.GetComments(post.ID, nil, 10, nil, "ASC")
+	rootComments, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: "ASC"})
 	require.NoError(t, err)
 	assert.False(t, hasMore)
 	assert.Len(t, rootComments, 2)
 	assert.Equal(t, rootComment1.ID, rootComments[0].ID)
 	assert.Equal(t, rootComment2.ID, rootComments[1].ID)
 
-	childrenOfRoot1, hasMore, err := commentService.GetComments(post.ID, &rootComment1.ID, 10, nil, "ASC")
+	childrenOfRoot1, hasMore, err := commentService.GetComments(post.ID, &rootComment1.ID, repositories.Page{Limit: 10, SortOrder: "ASC"})
 	require.NoError(t, err)
 	assert.False(t, hasMore)
 	assert.Len(t, childrenOfRoot1, 2)
 	assert.Equal(t, child1OfRoot1.ID, childrenOfRoot1[0].ID)
 	assert.Equal(t, child2OfRoot1.ID, childrenOfRoot1[1].ID)
 
-	grandchildren, hasMore, err := commentService.GetComments(post.ID, &child1OfRoot1.ID, 10, nil, "ASC")
+	grandchildren, hasMore, err := commentService.GetComments(post.ID, &child1OfRoot1.ID, repositories.Page{Limit: 10, SortOrder: "ASC"})
 	require.NoError(t, err)
 	assert.False(t, hasMore)
 	assert.Len(t, grandchildren, 2)
@@ -248,3 +257,911 @@ func TestNestedComments(t *testing.T) {
 	assert.Equal(t, rootComment1.ID, *child1OfRoot1.ParentID)
 	assert.Equal(t, child1OfRoot1.ID, *grandchild1.ParentID)
 }
+
+func TestSubscribeComments(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	root, err := commentService.AddComment(post.ID, "User1", "Root", nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	all := commentService.SubscribeComments(ctx, post.ID, nil)
+	replies := commentService.SubscribeComments(ctx, post.ID, &root.ID)
+
+	top, err := commentService.AddComment(post.ID, "User2", "Another root", nil)
+	require.NoError(t, err)
+	reply, err := commentService.AddComment(post.ID, "User3", "Reply", &root.ID)
+	require.NoError(t, err)
+
+	for _, expected := range []string{top.ID, reply.ID} {
+		select {
+		case comment := <-all:
+			assert.Equal(t, expected, comment.ID)
+		case <-time.After(time.Second):
+			t.Fatal("comment was not delivered")
+		}
+	}
+
+	select {
+	case comment := <-replies:
+		assert.Equal(t, reply.ID, comment.ID)
+	case <-time.After(time.Second):
+		t.Fatal("reply was not delivered")
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-all:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+}
+
+func TestAddComment_AfterCommentsClosed(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	_, err = commentService.AddComment(post.ID, "User1", "Before closing", nil)
+	require.NoError(t, err)
+
+	moderator := "moderator"
+	closed, err := postService.SetCommentsEnabled(post.ID, false, &moderator)
+	require.NoError(t, err)
+	assert.False(t, closed.AllowComments)
+	require.NotNil(t, closed.CommentsClosedBy)
+	assert.Equal(t, moderator, *closed.CommentsClosedBy)
+	assert.NotNil(t, closed.CommentsClosedAt)
+
+	_, err = commentService.AddComment(post.ID, "User2", "After closing", nil)
+	assert.ErrorIs(t, err, repositories.ErrCommentsDisabled)
+
+	reopened, err := postService.SetCommentsEnabled(post.ID, true, nil)
+	require.NoError(t, err)
+	assert.True(t, reopened.AllowComments)
+	assert.Nil(t, reopened.CommentsClosedBy)
+	assert.Nil(t, reopened.CommentsClosedAt)
+
+	_, err = commentService.AddComment(post.ID, "User2", "After reopening", nil)
+	assert.NoError(t, err)
+}
+
+func TestEditComment_KeepsRevisions(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	comment, err := commentService.AddComment(post.ID, "User1", "Frist", nil)
+	require.NoError(t, err)
+	assert.Nil(t, comment.EditedAt)
+
+	edited, err := commentService.EditComment(comment.ID, "First")
+	require.NoError(t, err)
+	assert.Equal(t, "First", edited.Text)
+	require.NotNil(t, edited.EditedAt)
+
+	_, err = commentService.EditComment(comment.ID, "First!")
+	require.NoError(t, err)
+
+	revisions, err := commentService.GetRevisions(comment.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "Frist", revisions[0].Text)
+	assert.Equal(t, comment.CreatedAt, revisions[0].CreatedAt)
+	assert.Equal(t, "First", revisions[1].Text)
+
+	comments, _, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "First!", comments[0].Text)
+
+	longText := make([]byte, 2001)
+	for i := range longText {
+		longText[i] = 'a'
+	}
+	_, err = commentService.EditComment(comment.ID, string(longText))
+	assert.ErrorIs(t, err, repositories.ErrTextTooLong)
+
+	_, err = commentService.EditComment("non-existent-id", "Text")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+func TestDeleteComment_KeepsThreadShape(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	parent, err := commentService.AddComment(post.ID, "User1", "Parent", nil)
+	require.NoError(t, err)
+	child, err := commentService.AddComment(post.ID, "User2", "Child", &parent.ID)
+	require.NoError(t, err)
+	_, err = commentService.EditComment(parent.ID, "Parent, edited")
+	require.NoError(t, err)
+
+	require.NoError(t, commentService.DeleteComment(parent.ID))
+
+	roots, _, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	assert.True(t, roots[0].Deleted)
+	assert.Equal(t, 1, roots[0].RepliesCount)
+	assert.Empty(t, roots[0].Text)
+	assert.Empty(t, roots[0].Author)
+
+	replies, _, err := commentService.GetComments(post.ID, &parent.ID, repositories.Page{Limit: 10, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	assert.Equal(t, child.ID, replies[0].ID)
+	assert.False(t, replies[0].Deleted)
+
+	revisions, err := commentService.GetRevisions(parent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	_, err = commentService.EditComment(parent.ID, "Resurrected")
+	assert.ErrorIs(t, err, repositories.ErrCommentDeleted)
+
+	_, err = commentService.AddComment(post.ID, "User3", "Reply to deleted", &parent.ID)
+	assert.ErrorIs(t, err, repositories.ErrCommentDeleted)
+
+	assert.ErrorIs(t, commentService.DeleteComment("non-existent-id"), repositories.ErrNotFound)
+}
+
+func TestGetComments_BackwardPagination(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	for _, text := range []string{"Comment 1", "Comment 2", "Comment 3", "Comment 4", "Comment 5"} {
+		_, err := commentService.AddComment(post.ID, "User", text, nil)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+
+	last, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, Backward: true, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, last, 2)
+	assert.True(t, hasMore)
+	assert.Equal(t, "Comment 4", last[0].Text)
+	assert.Equal(t, "Comment 5", last[1].Text)
+
+	earlier, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, Before: repositories.CommentCursor(last[0]), Backward: true, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, earlier, 2)
+	assert.True(t, hasMore)
+	assert.Equal(t, "Comment 2", earlier[0].Text)
+	assert.Equal(t, "Comment 3", earlier[1].Text)
+
+	earliest, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, Before: repositories.CommentCursor(earlier[0]), Backward: true, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, earliest, 1)
+	assert.False(t, hasMore)
+	assert.Equal(t, "Comment 1", earliest[0].Text)
+
+	newest, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, Backward: true, SortOrder: "DESC"})
+	require.NoError(t, err)
+	require.Len(t, newest, 2)
+	assert.True(t, hasMore)
+	assert.Equal(t, "Comment 2", newest[0].Text)
+	assert.Equal(t, "Comment 1", newest[1].Text)
+
+	between, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, After: repositories.CommentCursor(earliest[0]), Before: repositories.CommentCursor(last[1]), SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, between, 3)
+	assert.False(t, hasMore)
+	assert.Equal(t, "Comment 2", between[0].Text)
+	assert.Equal(t, "Comment 4", between[2].Text)
+}
+
+func TestGetRepliesPages(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	first, err := commentService.AddComment(post.ID, "Author", "First", nil)
+	require.NoError(t, err)
+	second, err := commentService.AddComment(post.ID, "Author", "Second", nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = commentService.AddComment(post.ID, "Author", "Reply", &first.ID)
+		require.NoError(t, err)
+	}
+
+	pages, err := commentService.GetRepliesPages([]string{first.ID, second.ID}, 2, "ASC")
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+
+	assert.Len(t, pages[first.ID].Comments, 2)
+	assert.True(t, pages[first.ID].HasMore)
+	assert.Equal(t, 3, pages[first.ID].TotalCount)
+
+	assert.Empty(t, pages[second.ID].Comments)
+	assert.False(t, pages[second.ID].HasMore)
+	assert.Equal(t, 0, pages[second.ID].TotalCount)
+
+	topLevel, err := commentService.GetTopLevelPages([]string{post.ID}, 10, "DESC")
+	require.NoError(t, err)
+	require.Len(t, topLevel[post.ID].Comments, 2)
+	assert.Equal(t, second.ID, topLevel[post.ID].Comments[0].ID)
+	assert.Equal(t, 2, topLevel[post.ID].TotalCount)
+}
+
+func TestRepliesCount_MaintainedOnCreate(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	parent, err := commentService.AddComment(post.ID, "User1", "Parent", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, parent.RepliesCount)
+
+	child, err := commentService.AddComment(post.ID, "User2", "Child", &parent.ID)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User3", "Child", &parent.ID)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User1", "Grandchild", &child.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, parent.RepliesCount, "returned comments must not change afterwards")
+
+	roots, _, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	assert.Equal(t, 2, roots[0].RepliesCount)
+
+	replies, _, err := commentService.GetComments(post.ID, &parent.ID, repositories.Page{Limit: 10, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+	assert.Equal(t, 1, replies[0].RepliesCount)
+	assert.Equal(t, 0, replies[1].RepliesCount)
+}
+
+func TestGetCommentTree(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	root1, err := commentService.AddComment(post.ID, "User1", "Root 1", nil)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User1", "Root 2", nil)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User1", "Root 3", nil)
+	require.NoError(t, err)
+
+	child1, err := commentService.AddComment(post.ID, "User2", "Child 1", &root1.ID)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = commentService.AddComment(post.ID, "User2", "Child", &root1.ID)
+		require.NoError(t, err)
+	}
+	grandchild, err := commentService.AddComment(post.ID, "User3", "Grandchild", &child1.ID)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User3", "Great-grandchild", &grandchild.ID)
+	require.NoError(t, err)
+
+	roots, hasMore, err := commentService.GetCommentTree(post.ID, nil, 3, 2)
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	require.Len(t, roots, 2)
+	assert.Equal(t, root1.ID, roots[0].Comment.ID)
+
+	children := roots[0].Children
+	require.Len(t, children, 2)
+	assert.True(t, roots[0].HasMoreChildren)
+	assert.Equal(t, child1.ID, children[0].Comment.ID)
+
+	require.Len(t, children[0].Children, 1)
+	leaf := children[0].Children[0]
+	assert.Equal(t, grandchild.ID, leaf.Comment.ID)
+	assert.Empty(t, leaf.Children)
+	assert.True(t, leaf.HasMoreChildren, "replies below maxDepth must be marked")
+
+	assert.Empty(t, roots[1].Children)
+	assert.False(t, roots[1].HasMoreChildren)
+
+	subtree, hasMore, err := commentService.GetCommentTree(post.ID, &child1.ID, 10, 10)
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	require.Len(t, subtree, 1)
+	assert.Equal(t, child1.ID, subtree[0].Comment.ID)
+	require.Len(t, subtree[0].Children, 1)
+	require.Len(t, subtree[0].Children[0].Children, 1)
+	assert.False(t, subtree[0].Children[0].HasMoreChildren)
+
+	_, _, err = commentService.GetCommentTree(post.ID, nil, 0, 10)
+	assert.Error(t, err)
+
+	_, _, err = commentService.GetCommentTree(post.ID, &post.ID, 3, 10)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+func TestGetAncestors(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	root, err := commentService.AddComment(post.ID, "User1", "Root", nil)
+	require.NoError(t, err)
+	child, err := commentService.AddComment(post.ID, "User2", "Child", &root.ID)
+	require.NoError(t, err)
+	grandchild, err := commentService.AddComment(post.ID, "User3", "Grandchild", &child.ID)
+	require.NoError(t, err)
+
+	found, err := commentService.GetComment(grandchild.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Grandchild", found.Text)
+
+	ancestors, err := commentService.GetAncestors(grandchild.ID)
+	require.NoError(t, err)
+	require.Len(t, ancestors, 2)
+	assert.Equal(t, root.ID, ancestors[0].ID)
+	assert.Equal(t, child.ID, ancestors[1].ID)
+
+	ancestors, err = commentService.GetAncestors(root.ID)
+	require.NoError(t, err)
+	assert.Empty(t, ancestors)
+
+	_, err = commentService.GetComment("non-existent-id")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+	_, err = commentService.GetAncestors("non-existent-id")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+func TestAddComment_MaxDepth(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{MaxDepth: 2})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	root, err := commentService.AddComment(post.ID, "User1", "Root", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, root.Depth)
+
+	child, err := commentService.AddComment(post.ID, "User2", "Child", &root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, child.Depth)
+
+	_, err = commentService.AddComment(post.ID, "User3", "Too deep", &child.ID)
+	assert.ErrorIs(t, err, repositories.ErrMaxDepthExceeded)
+
+	depth := 3
+	_, err = postService.SetMaxCommentDepth(post.ID, &depth)
+	require.NoError(t, err)
+
+	grandchild, err := commentService.AddComment(post.ID, "User3", "Grandchild", &child.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, grandchild.Depth)
+
+	unlimited := 0
+	_, err = postService.SetMaxCommentDepth(post.ID, &unlimited)
+	require.NoError(t, err)
+
+	deep, err := commentService.AddComment(post.ID, "User4", "Deep", &grandchild.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, deep.Depth)
+
+	negative := -1
+	_, err = postService.SetMaxCommentDepth(post.ID, &negative)
+	assert.Error(t, err)
+}
+
+func TestAddComment_FlattenDeepReplies(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{
+		MaxDepth:           3,
+		FlattenDeepReplies: true,
+	})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	root, err := commentService.AddComment(post.ID, "User1", "Root", nil)
+	require.NoError(t, err)
+	child, err := commentService.AddComment(post.ID, "User2", "Child", &root.ID)
+	require.NoError(t, err)
+	grandchild, err := commentService.AddComment(post.ID, "User3", "Grandchild", &child.ID)
+	require.NoError(t, err)
+
+	flattened, err := commentService.AddComment(post.ID, "User4", "Reply to grandchild", &grandchild.ID)
+	require.NoError(t, err)
+	assert.Equal(t, child.ID, *flattened.ParentID)
+	assert.Equal(t, 3, flattened.Depth)
+
+	_, err = commentService.AddComment(post.ID, "User4", "Reply", &flattened.ID)
+	require.NoError(t, err)
+
+	replies, _, err := commentService.GetComments(post.ID, &child.ID, repositories.Page{Limit: 10, SortOrder: "ASC"})
+	require.NoError(t, err)
+	assert.Len(t, replies, 3)
+
+	_, err = commentService.AddComment(post.ID, "User4", "Reply", &post.ID)
+	assert.ErrorIs(t, err, repositories.ErrParentNotFound)
+}
+
+func TestGetSubtreeCount(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	root, err := commentService.AddComment(post.ID, "User1", "Root", nil)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User1", "Other root", nil)
+	require.NoError(t, err)
+	child, err := commentService.AddComment(post.ID, "User2", "Child", &root.ID)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User3", "Grandchild", &child.ID)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User3", "Grandchild", &child.ID)
+	require.NoError(t, err)
+
+	total, err := commentService.GetSubtreeCount(post.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+
+	stored, err := postService.GetPost(post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, stored.CommentsTotal)
+
+	found, err := commentService.GetComment(root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, found.DescendantsCount)
+	assert.Equal(t, 1, found.RepliesCount)
+
+	below, err := commentService.GetSubtreeCount(post.ID, &root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, below)
+
+	direct, err := commentService.GetCommentsCount(post.ID, &root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, direct)
+
+	_, err = commentService.GetSubtreeCount(post.ID, &post.ID)
+	assert.ErrorIs(t, err, repositories.ErrParentNotFound)
+
+	_, err = commentService.GetSubtreeCount("non-existent-id", nil)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+func TestGetComments_RankedSortOrders(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	var roots []*models.Comment
+	for i := 0; i < 3; i++ {
+		root, err := commentService.AddComment(post.ID, "User", "Root", nil)
+		require.NoError(t, err)
+		roots = append(roots, root)
+		time.Sleep(time.Millisecond)
+	}
+
+	child, err := commentService.AddComment(post.ID, "User", "Reply", &roots[0].ID)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User", "Nested reply", &child.ID)
+	require.NoError(t, err)
+
+	ids := func(comments []*models.Comment) []string {
+		result := make([]string, len(comments))
+		for i, comment := range comments {
+			result[i] = comment.ID
+		}
+		return result
+	}
+
+	active, _, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: "LAST_ACTIVITY"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{roots[0].ID, roots[2].ID, roots[1].ID}, ids(active))
+
+	hot, _, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: "HOT"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{roots[2].ID, roots[1].ID, roots[0].ID}, ids(hot))
+
+	first, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, SortOrder: "TOP"})
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{roots[2].ID, roots[1].ID}, ids(first))
+
+	rest, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{
+		Limit:     2,
+		After:     repositories.CommentCursor(first[1]),
+		SortOrder: "TOP",
+	})
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{roots[0].ID}, ids(rest))
+
+	_, _, err = commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: "RANDOM"})
+	assert.Error(t, err)
+}
+
+func TestPinComment(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	var comments []*models.Comment
+	for _, text := range []string{"Comment 1", "Comment 2", "Comment 3", "Comment 4"} {
+		comment, err := commentService.AddComment(post.ID, "User", text, nil)
+		require.NoError(t, err)
+		comments = append(comments, comment)
+	}
+	reply, err := commentService.AddComment(post.ID, "User", "Reply", &comments[0].ID)
+	require.NoError(t, err)
+
+	_, err = commentService.PinComment(comments[0].ID, "User")
+	assert.ErrorIs(t, err, repositories.ErrNotPostAuthor)
+
+	_, err = commentService.PinComment(reply.ID, "Author")
+	assert.Error(t, err)
+
+	for _, comment := range comments[:3] {
+		pinned, err := commentService.PinComment(comment.ID, "Author")
+		require.NoError(t, err)
+		assert.NotNil(t, pinned.PinnedAt)
+	}
+
+	// Pinning again changes nothing, a further pin exceeds the limit.
+	_, err = commentService.PinComment(comments[0].ID, "Author")
+	require.NoError(t, err)
+	_, err = commentService.PinComment(comments[3].ID, "Author")
+	assert.ErrorIs(t, err, repositories.ErrPinLimitReached)
+
+	unpinned, err := commentService.UnpinComment(comments[1].ID, "Author")
+	require.NoError(t, err)
+	assert.Nil(t, unpinned.PinnedAt)
+
+	_, err = commentService.PinComment(comments[3].ID, "Author")
+	require.NoError(t, err)
+}
+
+func TestGetComments_PinnedFirst(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	ids := make(map[string]string)
+	for _, text := range []string{"Comment 1", "Comment 2", "Comment 3", "Comment 4", "Comment 5"} {
+		comment, err := commentService.AddComment(post.ID, "User", text, nil)
+		require.NoError(t, err)
+		ids[text] = comment.ID
+	}
+
+	for _, text := range []string{"Comment 4", "Comment 2"} {
+		_, err := commentService.PinComment(ids[text], "Author")
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+
+	texts := func(comments []*models.Comment) []string {
+		result := make([]string, len(comments))
+		for i, comment := range comments {
+			result[i] = comment.Text
+		}
+		return result
+	}
+
+	// Walk forward one comment at a time, crossing from the pinned comments
+	// into the rest of the level.
+	var walked []*models.Comment
+	page := repositories.Page{Limit: 1, SortOrder: "DESC"}
+	for {
+		comments, hasMore, err := commentService.GetComments(post.ID, nil, page)
+		require.NoError(t, err)
+		walked = append(walked, comments...)
+		if !hasMore {
+			break
+		}
+		page.After = repositories.CommentCursor(comments[len(comments)-1])
+	}
+	assert.Equal(t, []string{"Comment 4", "Comment 2", "Comment 5", "Comment 3", "Comment 1"}, texts(walked))
+
+	first, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 3, SortOrder: "ASC"})
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"Comment 4", "Comment 2", "Comment 1"}, texts(first))
+
+	// A full page of pinned comments still reports the rest.
+	pinned, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, SortOrder: "ASC"})
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"Comment 4", "Comment 2"}, texts(pinned))
+
+	before, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 2, Before: repositories.CommentCursor(first[2]), Backward: true, SortOrder: "ASC"})
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"Comment 4", "Comment 2"}, texts(before))
+
+	last, hasMore, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 4, Backward: true, SortOrder: "ASC"})
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"Comment 2", "Comment 1", "Comment 3", "Comment 5"}, texts(last))
+
+	pages, err := commentService.GetTopLevelPages([]string{post.ID}, 3, "TOP")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Comment 4", "Comment 2"}, texts(pages[post.ID].Comments[:2]))
+	assert.Equal(t, 5, pages[post.ID].TotalCount)
+}
+
+func TestAddComment_LockedThread(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	root, err := commentService.AddComment(post.ID, "User", "Root", nil)
+	require.NoError(t, err)
+	child, err := commentService.AddComment(post.ID, "User", "Child", &root.ID)
+	require.NoError(t, err)
+
+	// Only the post author moderates the thread.
+	_, err = commentService.SetThreadLocked(root.ID, true, "User")
+	assert.ErrorIs(t, err, repositories.ErrNotPostAuthor)
+
+	locked, err := commentService.SetThreadLocked(root.ID, true, "Author")
+	require.NoError(t, err)
+	assert.True(t, locked.Locked)
+
+	_, err = commentService.AddComment(post.ID, "User", "Reply", &root.ID)
+	assert.ErrorIs(t, err, repositories.ErrThreadLocked)
+	_, err = commentService.AddComment(post.ID, "User", "Deep reply", &child.ID)
+	assert.ErrorIs(t, err, repositories.ErrThreadLocked)
+
+	// The rest of the post is not affected.
+	_, err = commentService.AddComment(post.ID, "User", "Another root", nil)
+	require.NoError(t, err)
+
+	_, err = commentService.SetThreadLocked(root.ID, false, "User")
+	assert.ErrorIs(t, err, repositories.ErrNotPostAuthor)
+	_, err = commentService.SetThreadLocked(root.ID, false, "Author")
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User", "Deep reply", &child.ID)
+	require.NoError(t, err)
+}
+
+func TestAddComment_ArchivedPost(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+	comment, err := commentService.AddComment(post.ID, "User", "Comment", nil)
+	require.NoError(t, err)
+
+	archived, err := postService.ArchiveOldPosts(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, archived)
+
+	time.Sleep(time.Millisecond)
+	archived, err = postService.ArchiveOldPosts(0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+
+	stored, err := postService.GetPost(post.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.ArchivedAt)
+
+	_, err = commentService.AddComment(post.ID, "User", "Late comment", nil)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = commentService.AddComment(post.ID, "User", "Late reply", &comment.ID)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+
+	// Archived posts are not archived again.
+	archived, err = postService.ArchiveOldPosts(0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, archived)
+}
+
+func TestArchivedPost_ReadOnly(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	voteService := services.NewVoteService(postRepo, commentRepo)
+	reactionService := services.NewReactionService(memory.NewReactionRepository(), commentRepo, postRepo, []string{"👍"})
+
+	post, err := postService.CreatePost("Test Post", "Content", "Author", true)
+	require.NoError(t, err)
+	comment, err := commentService.AddComment(post.ID, "User", "Comment", nil)
+	require.NoError(t, err)
+	_, err = commentService.PinComment(comment.ID, "Author")
+	require.NoError(t, err)
+	_, err = reactionService.AddReaction(comment.ID, "👍", "User")
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+	archived, err := postService.ArchiveOldPosts(0)
+	require.NoError(t, err)
+	require.Equal(t, 1, archived)
+
+	title := "New title"
+	depth := 3
+	category := "news"
+
+	_, err = commentService.EditComment(comment.ID, "Edited")
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	err = commentService.DeleteComment(comment.ID)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = commentService.PinComment(comment.ID, "Author")
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = commentService.UnpinComment(comment.ID, "Author")
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = commentService.SetThreadLocked(comment.ID, true, "Author")
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+
+	_, err = voteService.Vote(comment.ID, "User", 1)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = voteService.Vote(post.ID, "User", 1)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+
+	_, err = reactionService.AddReaction(comment.ID, "👍", "Author")
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = reactionService.RemoveReaction(comment.ID, "👍", "User")
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+
+	_, err = postService.UpdatePost(post.ID, &title, nil)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = postService.SetCommentsEnabled(post.ID, false, nil)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = postService.SetMaxCommentDepth(post.ID, &depth)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = postService.SetTags(post.ID, []string{"go"})
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+	_, err = postService.SetCategory(post.ID, &category)
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+
+	// Nothing was changed.
+	stored, err := commentService.GetComment(comment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Comment", stored.Text)
+	assert.False(t, stored.Deleted)
+	assert.NotNil(t, stored.PinnedAt)
+	assert.False(t, stored.Locked)
+	assert.Zero(t, stored.Score)
+
+	storedPost, err := postService.GetPost(post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Test Post", storedPost.Title)
+	assert.True(t, storedPost.AllowComments)
+	assert.Empty(t, storedPost.Tags)
+	assert.Nil(t, storedPost.Category)
+}
+
+func TestGetMentions(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	first, err := postService.CreatePost("First", "Content", "Author", true)
+	require.NoError(t, err)
+	second, err := postService.CreatePost("Second", "Content", "Author", true)
+	require.NoError(t, err)
+
+	greeting, err := commentService.AddComment(first.ID, "User", "hi @alice and @bob", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, greeting.Mentions)
+
+	time.Sleep(time.Millisecond)
+	question, err := commentService.AddComment(second.ID, "User", "@alice what do you think?", nil)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = commentService.AddComment(second.ID, "User", "nobody tagged", nil)
+	require.NoError(t, err)
+
+	page, hasMore, err := commentService.GetMentions("alice", repositories.Page{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.True(t, hasMore)
+	assert.Equal(t, question.ID, page[0].ID)
+
+	page, hasMore, err = commentService.GetMentions("alice", repositories.Page{Limit: 1, After: repositories.CommentCursor(page[0])})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.False(t, hasMore)
+	assert.Equal(t, greeting.ID, page[0].ID)
+
+	count, err := commentService.GetMentionsCount("alice")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Editing and deleting keep the index in step with the text.
+	_, err = commentService.EditComment(greeting.ID, "hi @carol")
+	require.NoError(t, err)
+	page, _, err = commentService.GetMentions("bob", repositories.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page)
+	page, _, err = commentService.GetMentions("carol", repositories.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+
+	require.NoError(t, commentService.DeleteComment(question.ID))
+	count, err = commentService.GetMentionsCount("alice")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+// FollowService lets users follow posts and comment threads they did not
+// start. Followers are notified about new comments by the
+// NotificationService.
+type FollowService struct {
+	repo        repositories.FollowRepository
+	postRepo    repositories.PostRepository
+	commentRepo repositories.CommentRepository
+}
+
+func NewFollowService(repo repositories.FollowRepository, postRepo repositories.PostRepository, commentRepo repositories.CommentRepository) *FollowService {
+	return &FollowService{
+		repo:        repo,
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+	}
+}
+
+// FollowPost subscribes the user to every new comment on the post and
+// returns the post.
+func (s *FollowService) FollowPost(postID, user string) (*models.Post, error) {
+	if user == "" {
+		return nil, errors.New("user must not be empty")
+	}
+
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.FollowPost(postID, user); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// UnfollowPost stops the notifications about the post and returns the post.
+func (s *FollowService) UnfollowPost(postID, user string) (*models.Post, error) {
+	if user == "" {
+		return nil, errors.New("user must not be empty")
+	}
+
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UnfollowPost(postID, user); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// FollowThread subscribes the user to every new comment below the comment,
+// at any depth, and returns the comment.
+func (s *FollowService) FollowThread(commentID, user string) (*models.Comment, error) {
+	if user == "" {
+		return nil, errors.New("user must not be empty")
+	}
+
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.FollowThread(commentID, user); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// UnfollowThread stops the notifications about the thread below the comment
+// and returns the comment. Following the post is not affected.
+func (s *FollowService) UnfollowThread(commentID, user string) (*models.Comment, error) {
+	if user == "" {
+		return nil, errors.New("user must not be empty")
+	}
+
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UnfollowThread(commentID, user); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetFollowersCounts returns the number of followers of each post; posts
+// without followers are missing from the map.
+func (s *FollowService) GetFollowersCounts(postIDs []string) (map[string]int, error) {
+	return s.repo.CountPostFollowers(postIDs)
+}
+
+// GetFollowedPosts reports which of the posts the user follows.
+func (s *FollowService) GetFollowedPosts(user string, postIDs []string) (map[string]bool, error) {
+	return s.repo.GetFollowedPosts(user, postIDs)
+}
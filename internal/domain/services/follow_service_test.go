@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/services"
+	"posts_comments_service/internal/repository/memory"
+)
+
+func TestFollow(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+	followRepo := memory.NewFollowRepository()
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	followService := services.NewFollowService(followRepo, postRepo, commentRepo)
+	notificationService := services.NewNotificationService(memory.NewNotificationRepository(), commentRepo, followRepo)
+	commentService.Observe(notificationService)
+
+	post, err := postService.CreatePost("Post", "Content", "alice", true)
+	require.NoError(t, err)
+	other, err := postService.CreatePost("Other", "Content", "alice", true)
+	require.NoError(t, err)
+	root, err := commentService.AddComment(post.ID, "bob", "root", nil)
+	require.NoError(t, err)
+
+	_, err = followService.FollowPost(post.ID, "carol")
+	require.NoError(t, err)
+	// Following twice is a no-op.
+	_, err = followService.FollowPost(post.ID, "carol")
+	require.NoError(t, err)
+	_, err = followService.FollowPost(post.ID, "dave")
+	require.NoError(t, err)
+	_, err = followService.FollowThread(root.ID, "erin")
+	require.NoError(t, err)
+	_, err = followService.FollowThread(root.ID, "dave")
+	require.NoError(t, err)
+
+	_, err = followService.FollowPost("missing", "carol")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+	_, err = followService.FollowThread(root.ID, "")
+	assert.Error(t, err)
+	_, err = followService.UnfollowPost(post.ID, "")
+	assert.Error(t, err)
+	_, err = followService.UnfollowThread(root.ID, "")
+	assert.Error(t, err)
+
+	counts, err := followService.GetFollowersCounts([]string{post.ID, other.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{post.ID: 2}, counts)
+
+	followed, err := followService.GetFollowedPosts("carol", []string{post.ID, other.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{post.ID: true}, followed)
+
+	reply, err := commentService.AddComment(post.ID, "frank", "reply", &root.ID)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	deeper, err := commentService.AddComment(post.ID, "frank", "deeper", &reply.ID)
+	require.NoError(t, err)
+
+	inbox := func(user string) []*models.Notification {
+		notifications, _, err := notificationService.GetNotifications(user, false, repositories.Page{Limit: 10})
+		require.NoError(t, err)
+		return notifications
+	}
+
+	// Thread followers hear about replies at any depth, and a user following
+	// both the thread and the post is notified once.
+	erin := inbox("erin")
+	require.Len(t, erin, 2)
+	assert.Equal(t, deeper.ID, erin[0].CommentID)
+	assert.Equal(t, constants.NotificationThread, erin[0].Kind)
+	dave := inbox("dave")
+	require.Len(t, dave, 2)
+	assert.Equal(t, constants.NotificationThread, dave[0].Kind)
+
+	carol := inbox("carol")
+	require.Len(t, carol, 2)
+	assert.Equal(t, constants.NotificationPost, carol[0].Kind)
+
+	_, err = followService.UnfollowPost(post.ID, "carol")
+	require.NoError(t, err)
+	_, err = followService.UnfollowThread(root.ID, "erin")
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "frank", "again", &root.ID)
+	require.NoError(t, err)
+	assert.Len(t, inbox("carol"), 2)
+	assert.Len(t, inbox("erin"), 2)
+	assert.Len(t, inbox("dave"), 3)
+
+	counts, err = followService.GetFollowersCounts([]string{post.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{post.ID: 1}, counts)
+}
+
+func TestFollow_DeletedWithPost(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	followRepo := memory.NewFollowRepository()
+	commentRepo := memory.NewCommentRepository(postRepo, followRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	followService := services.NewFollowService(followRepo, postRepo, commentRepo)
+
+	post, err := postService.CreatePost("Post", "Content", "alice", true)
+	require.NoError(t, err)
+	root, err := commentService.AddComment(post.ID, "bob", "root", nil)
+	require.NoError(t, err)
+	_, err = followService.FollowPost(post.ID, "carol")
+	require.NoError(t, err)
+	_, err = followService.FollowThread(root.ID, "dave")
+	require.NoError(t, err)
+
+	require.NoError(t, postService.DeletePost(post.ID))
+
+	counts, err := followRepo.CountPostFollowers([]string{post.ID})
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+	followers, err := followRepo.GetThreadFollowers([]string{root.ID})
+	require.NoError(t, err)
+	assert.Empty(t, followers)
+}
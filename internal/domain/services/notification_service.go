@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/events"
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+// NotificationService keeps the inbox of every user. It observes new
+// comments and notifies the author of the parent comment, the mentioned
+// users, the author of the post and the followers of the thread and the
+// post, never the comment author.
+type NotificationService struct {
+	repo        repositories.NotificationRepository
+	commentRepo repositories.CommentRepository
+	followRepo  repositories.FollowRepository
+	broker      *events.Broker[*models.Notification]
+}
+
+func NewNotificationService(repo repositories.NotificationRepository, commentRepo repositories.CommentRepository, followRepo repositories.FollowRepository) *NotificationService {
+	return &NotificationService{
+		repo:        repo,
+		commentRepo: commentRepo,
+		followRepo:  followRepo,
+		broker:      events.NewBroker[*models.Notification](),
+	}
+}
+
+// CommentAdded implements CommentObserver. Failures are logged: the comment
+// is stored already and must not be reported as failed.
+func (s *NotificationService) CommentAdded(post *models.Post, comment *models.Comment) {
+	notifications, err := s.notificationsFor(post, comment)
+	if err == nil && len(notifications) > 0 {
+		err = s.repo.Create(notifications)
+	}
+	if err != nil {
+		log.Printf("Failed to notify about comment %s: %v", comment.ID, err)
+		return
+	}
+
+	for _, notification := range notifications {
+		s.broker.Publish(notification.Recipient, notification)
+	}
+}
+
+// notificationsFor builds one notification per recipient, of the first kind
+// that applies: reply, mention, comment on the post, comment in a followed
+// thread, comment on a followed post.
+func (s *NotificationService) notificationsFor(post *models.Post, comment *models.Comment) ([]*models.Notification, error) {
+	var notifications []*models.Notification
+	notified := map[string]bool{comment.Author: true}
+	notify := func(recipient, kind string) {
+		if recipient == "" || notified[recipient] {
+			return
+		}
+		notified[recipient] = true
+		notifications = append(notifications, &models.Notification{
+			ID:        uuid.New().String(),
+			Recipient: recipient,
+			Kind:      kind,
+			Actor:     comment.Author,
+			PostID:    comment.PostID,
+			CommentID: comment.ID,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	// Every ancestor heads a thread the comment belongs to.
+	var threadIDs []string
+	if comment.ParentID != nil {
+		parent, err := s.commentRepo.GetByID(*comment.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		notify(parent.Author, constants.NotificationReply)
+
+		ancestors, err := s.commentRepo.GetAncestors(comment.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			threadIDs = append(threadIDs, ancestor.ID)
+		}
+	}
+	for _, user := range comment.Mentions {
+		notify(user, constants.NotificationMention)
+	}
+	notify(post.Author, constants.NotificationPostComment)
+
+	if len(threadIDs) > 0 {
+		followers, err := s.followRepo.GetThreadFollowers(threadIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range followers {
+			notify(user, constants.NotificationThread)
+		}
+	}
+
+	followers, err := s.followRepo.GetPostFollowers(post.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range followers {
+		notify(user, constants.NotificationPost)
+	}
+
+	return notifications, nil
+}
+
+// GetNotifications pages the inbox of the user, newest first.
+func (s *NotificationService) GetNotifications(user string, unreadOnly bool, page repositories.Page) ([]*models.Notification, bool, error) {
+	return s.repo.List(user, unreadOnly, page)
+}
+
+func (s *NotificationService) GetUnreadCount(user string) (int, error) {
+	return s.repo.CountUnread(user)
+}
+
+// MarkRead marks notifications of the user as read and returns how many
+// were unread.
+func (s *NotificationService) MarkRead(user string, ids []string) (int, error) {
+	if user == "" {
+		return 0, errors.New("user must not be empty")
+	}
+	return s.repo.MarkRead(user, ids)
+}
+
+// SubscribeNotifications streams new notifications of the user until ctx is done.
+func (s *NotificationService) SubscribeNotifications(ctx context.Context, user string) <-chan *models.Notification {
+	source, unsubscribe := s.broker.Subscribe(user)
+	out := make(chan *models.Notification, 1)
+
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case notification, ok := <-source:
+				if !ok {
+					return
+				}
+				select {
+				case out <- notification:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,155 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/services"
+	"posts_comments_service/internal/repository/memory"
+)
+
+func TestNotifications(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	notificationService := services.NewNotificationService(memory.NewNotificationRepository(), commentRepo, memory.NewFollowRepository())
+	commentService.Observe(notificationService)
+
+	post, err := postService.CreatePost("Post", "Content", "alice", true)
+	require.NoError(t, err)
+
+	// The post author commenting on their own post notifies nobody.
+	root, err := commentService.AddComment(post.ID, "alice", "what do you think?", nil)
+	require.NoError(t, err)
+	count, err := notificationService.GetUnreadCount("alice")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	time.Sleep(time.Millisecond)
+	// The reply mentions alice as well, but alice is notified only once.
+	reply, err := commentService.AddComment(post.ID, "bob", "@alice @carol @bob agreed", &root.ID)
+	require.NoError(t, err)
+
+	alice, _, err := notificationService.GetNotifications("alice", false, repositories.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, alice, 1)
+	assert.Equal(t, constants.NotificationReply, alice[0].Kind)
+	assert.Equal(t, "bob", alice[0].Actor)
+	assert.Equal(t, reply.ID, alice[0].CommentID)
+
+	carol, _, err := notificationService.GetNotifications("carol", false, repositories.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, carol, 1)
+	assert.Equal(t, constants.NotificationMention, carol[0].Kind)
+
+	bob, _, err := notificationService.GetNotifications("bob", false, repositories.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, bob)
+
+	time.Sleep(time.Millisecond)
+	_, err = commentService.AddComment(post.ID, "carol", "nice post", nil)
+	require.NoError(t, err)
+
+	page, hasMore, err := notificationService.GetNotifications("alice", false, repositories.Page{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.True(t, hasMore)
+	assert.Equal(t, constants.NotificationPostComment, page[0].Kind)
+	newest := page[0]
+
+	page, hasMore, err = notificationService.GetNotifications("alice", false, repositories.Page{Limit: 1, After: repositories.NotificationCursor(page[0])})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.False(t, hasMore)
+	assert.Equal(t, alice[0].ID, page[0].ID)
+
+	marked, err := notificationService.MarkRead("alice", []string{newest.ID, newest.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, marked)
+	// Someone else's notifications are left alone.
+	marked, err = notificationService.MarkRead("bob", []string{alice[0].ID})
+	require.NoError(t, err)
+	assert.Zero(t, marked)
+
+	unread, _, err := notificationService.GetNotifications("alice", true, repositories.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, unread, 1)
+	assert.Equal(t, alice[0].ID, unread[0].ID)
+
+	count, err = notificationService.GetUnreadCount("alice")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = notificationService.MarkRead("", []string{newest.ID})
+	assert.Error(t, err)
+}
+
+func TestSubscribeNotifications(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	notificationService := services.NewNotificationService(memory.NewNotificationRepository(), commentRepo, memory.NewFollowRepository())
+	commentService.Observe(notificationService)
+
+	post, err := postService.CreatePost("Post", "Content", "alice", true)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notifications := notificationService.SubscribeNotifications(ctx, "alice")
+
+	comment, err := commentService.AddComment(post.ID, "bob", "hello", nil)
+	require.NoError(t, err)
+
+	select {
+	case notification := <-notifications:
+		assert.Equal(t, comment.ID, notification.CommentID)
+		assert.Equal(t, constants.NotificationPostComment, notification.Kind)
+	case <-time.After(time.Second):
+		t.Fatal("notification was not delivered")
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-notifications:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+}
+
+func TestNotifications_DeletedWithPost(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	notificationRepo := memory.NewNotificationRepository()
+	commentRepo := memory.NewCommentRepository(postRepo, notificationRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	notificationService := services.NewNotificationService(notificationRepo, commentRepo, memory.NewFollowRepository())
+	commentService.Observe(notificationService)
+
+	post, err := postService.CreatePost("Post", "Content", "alice", true)
+	require.NoError(t, err)
+	other, err := postService.CreatePost("Other", "Content", "alice", true)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "bob", "hi", nil)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(other.ID, "bob", "hi", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, postService.DeletePost(post.ID))
+
+	notifications, _, err := notificationService.GetNotifications("alice", false, repositories.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, other.ID, notifications[0].PostID)
+}
@@ -3,7 +3,10 @@ package services
 import (
 	"errors"
 	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/tags"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"posts_comments_service/internal/domain/models"
@@ -11,11 +14,15 @@ import (
 )
 
 type PostService struct {
-	repo repositories.PostRepository
+	repo        repositories.PostRepository
+	commentRepo repositories.CommentRepository
 }
 
-func NewPostService(repo repositories.PostRepository) *PostService {
-	return &PostService{repo: repo}
+func NewPostService(repo repositories.PostRepository, commentRepo repositories.CommentRepository) *PostService {
+	return &PostService{
+		repo:        repo,
+		commentRepo: commentRepo,
+	}
 }
 
 func (s *PostService) CreatePost(title, content, author string, allowComments bool) (*models.Post, error) {
@@ -25,7 +32,7 @@ func (s *PostService) CreatePost(title, content, author string, allowComments bo
 		Content:       content,
 		Author:        author,
 		AllowComments: allowComments,
-		CreatedAt:     time.Now().Format(time.RFC3339),
+		CreatedAt:     now(),
 	}
 
 	if err := s.repo.Create(post); err != nil {
@@ -39,9 +46,173 @@ func (s *PostService) GetPost(id string) (*models.Post, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *PostService) GetPosts(limit int, after *string, sortOrder string) ([]*models.Post, error) {
-	if sortOrder != constants.SortAsc && sortOrder != constants.SortDesc {
-		return nil, errors.New("invalid sort order")
+// GetPostsByIDs returns the posts with the ids; missing posts are missing
+// from the map.
+func (s *PostService) GetPostsByIDs(ids []string) (map[string]*models.Post, error) {
+	return s.repo.GetByIDs(ids)
+}
+
+// ensureWritable rejects changes to an archived post and to anything on it:
+// archived posts are read-only.
+func ensureWritable(post *models.Post) error {
+	if post.ArchivedAt != nil {
+		return repositories.ErrPostArchived
+	}
+	return nil
+}
+
+// writablePost loads a post that may still be changed.
+func (s *PostService) writablePost(id string) (*models.Post, error) {
+	post, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureWritable(post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// UpdatePost changes the title and/or content of a post; nil fields are left as is.
+func (s *PostService) UpdatePost(id string, title, content *string) (*models.Post, error) {
+	post, err := s.writablePost(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *post
+	if title != nil {
+		updated.Title = *title
+	}
+	if content != nil {
+		updated.Content = *content
+	}
+
+	if err := s.repo.Update(&updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeletePost removes a post together with all of its comments.
+func (s *PostService) DeletePost(id string) error {
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	return s.commentRepo.DeleteByPostID(id)
+}
+
+// SetCommentsEnabled opens or closes the comment thread of a post. When closing,
+// the moderator and the time are recorded; reopening clears them.
+func (s *PostService) SetCommentsEnabled(id string, enabled bool, moderator *string) (*models.Post, error) {
+	var closedAt *string
+	if enabled {
+		moderator = nil
+	} else {
+		timestamp := now()
+		closedAt = &timestamp
+	}
+
+	if _, err := s.writablePost(id); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetCommentsEnabled(id, enabled, moderator, closedAt); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(id)
+}
+
+// SetMaxCommentDepth sets how deep the comment threads of a post may grow.
+// nil falls back to the server default and 0 allows unlimited nesting.
+func (s *PostService) SetMaxCommentDepth(id string, maxDepth *int) (*models.Post, error) {
+	if maxDepth != nil && *maxDepth < 0 {
+		return nil, errors.New("max comment depth must not be negative")
 	}
-	return s.repo.List(limit, after, sortOrder)
+
+	if _, err := s.writablePost(id); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetMaxCommentDepth(id, maxDepth); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(id)
+}
+
+// ArchiveOldPosts archives the posts older than maxAge and returns how many
+// were archived.
+func (s *PostService) ArchiveOldPosts(maxAge time.Duration) (int, error) {
+	t := currentTime()
+	return s.repo.Archive(t.Add(-maxAge).Format(constants.TimeFormat), t.Format(constants.TimeFormat))
+}
+
+// SetTags replaces the tags of a post. Tags are stored lowercase, each once.
+func (s *PostService) SetTags(id string, rawTags []string) (*models.Post, error) {
+	normalized, err := tags.Normalize(rawTags)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.writablePost(id); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetTags(id, normalized); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(id)
+}
+
+// SetCategory puts a post in a category; nil or a blank name removes it
+// from its category.
+func (s *PostService) SetCategory(id string, category *string) (*models.Post, error) {
+	if category != nil {
+		trimmed := strings.TrimSpace(*category)
+		category = &trimmed
+		if trimmed == "" {
+			category = nil
+		} else if utf8.RuneCountInString(trimmed) > constants.MaxCategoryLength {
+			return nil, errors.New("category exceeds the 64 character limit")
+		}
+	}
+
+	if _, err := s.writablePost(id); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetCategory(id, category); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(id)
+}
+
+// GetTags returns the tags starting with prefix, most used first.
+func (s *PostService) GetTags(prefix string, limit int) ([]*models.TagCount, error) {
+	if limit < 0 {
+		return nil, errors.New("first must not be negative")
+	}
+	return s.repo.ListTags(tags.Canonical(prefix), limit)
+}
+
+// GetPosts pages the posts matching the filter; its tag and category are
+// looked up in stored form.
+func (s *PostService) GetPosts(filter repositories.PostFilter, page repositories.Page) ([]*models.Post, bool, error) {
+	if page.SortOrder != constants.SortAsc && page.SortOrder != constants.SortDesc {
+		return nil, false, errors.New("invalid sort order")
+	}
+	filter.Tag = tags.Canonical(filter.Tag)
+	filter.Category = strings.TrimSpace(filter.Category)
+	return s.repo.List(filter, page)
+}
+
+func (s *PostService) GetPostsCount(filter repositories.PostFilter) (int, error) {
+	filter.Tag = tags.Canonical(filter.Tag)
+	filter.Category = strings.TrimSpace(filter.Category)
+	return s.repo.Count(filter)
 }
@@ -2,8 +2,10 @@ package services_test
 
 import (
 	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/models"
 	"posts_comments_service/internal/domain/repositories"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"posts_comments_service/internal/domain/services"
@@ -12,7 +14,7 @@ import (
 
 func TestCreatePost_Success(t *testing.T) {
 	memRepo := memory.NewPostRepository()
-	service := services.NewPostService(memRepo)
+	service := services.NewPostService(memRepo, memory.NewCommentRepository(memRepo))
 
 	post, err := service.CreatePost("Title", "Content", "Author", true)
 	assert.NoError(t, err)
@@ -22,7 +24,7 @@ func TestCreatePost_Success(t *testing.T) {
 
 func TestGetPost_Success(t *testing.T) {
 	memRepo := memory.NewPostRepository()
-	service := services.NewPostService(memRepo)
+	service := services.NewPostService(memRepo, memory.NewCommentRepository(memRepo))
 
 	created, _ := service.CreatePost("Title", "Content", "Author", true)
 
@@ -34,19 +36,73 @@ func TestGetPost_Success(t *testing.T) {
 
 func TestGetPosts(t *testing.T) {
 	memRepo := memory.NewPostRepository()
-	service := services.NewPostService(memRepo)
+	service := services.NewPostService(memRepo, memory.NewCommentRepository(memRepo))
 
 	_, _ = service.CreatePost("Title 1", "Content", "Author", true)
 	_, _ = service.CreatePost("Title 2", "Content", "Author", true)
 
-	posts, err := service.GetPosts(10, nil, "DESC")
+	posts, hasMore, err := service.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 10, SortOrder: "DESC"})
 	assert.NoError(t, err)
 	assert.Len(t, posts, 2)
+	assert.False(t, hasMore)
+}
+
+func TestGetPosts_WithPagination(t *testing.T) {
+	repo := memory.NewPostRepository()
+	service := services.NewPostService(repo, memory.NewCommentRepository(repo))
+
+	for _, title := range []string{"Title 1", "Title 2", "Title 3"} {
+		_, err := service.CreatePost(title, "Content", "Author", true)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+
+	posts, hasMore, err := service.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 2, SortOrder: "DESC"})
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	assert.True(t, hasMore)
+	assert.Equal(t, "Title 3", posts[0].Title)
+
+	nextPosts, hasMore, err := service.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 2, After: repositories.PostCursor(posts[1]), SortOrder: "DESC"})
+	require.NoError(t, err)
+	require.Len(t, nextPosts, 1)
+	assert.False(t, hasMore)
+	assert.Equal(t, "Title 1", nextPosts[0].Title)
+
+	posts, hasMore, err = service.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 2, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	assert.True(t, hasMore)
+	assert.Equal(t, "Title 1", posts[0].Title)
+
+	earlier, hasMore, err := service.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 2, Before: repositories.PostCursor(posts[0]), Backward: true, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, earlier, 0)
+	assert.False(t, hasMore)
+
+	latest, hasMore, err := service.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 2, Backward: true, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	assert.True(t, hasMore)
+	assert.Equal(t, "Title 2", latest[0].Title)
+	assert.Equal(t, "Title 3", latest[1].Title)
+
+	count, err := service.GetPostsCount(repositories.PostFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// Paging goes on after the cursor post is deleted.
+	require.NoError(t, service.DeletePost(latest[0].ID))
+	after, hasMore, err := service.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 2, After: repositories.PostCursor(latest[0]), SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, after, 1)
+	assert.False(t, hasMore)
+	assert.Equal(t, "Title 3", after[0].Title)
 }
 
 func TestCreatePost_InvalidAuthor(t *testing.T) {
 	repo := memory.NewPostRepository()
-	service := services.NewPostService(repo)
+	service := services.NewPostService(repo, memory.NewCommentRepository(repo))
 
 	post, err := service.CreatePost("Title", "Content", "", true)
 	require.NoError(t, err)
@@ -55,7 +111,7 @@ func TestCreatePost_InvalidAuthor(t *testing.T) {
 
 func TestGetPost_NotFound(t *testing.T) {
 	repo := memory.NewPostRepository()
-	service := services.NewPostService(repo)
+	service := services.NewPostService(repo, memory.NewCommentRepository(repo))
 
 	post, err := service.GetPost("non-existent-id")
 	assert.Nil(t, post)
@@ -64,8 +120,163 @@ func TestGetPost_NotFound(t *testing.T) {
 
 func TestGetPosts_SortOrderValidation(t *testing.T) {
 	repo := memory.NewPostRepository()
-	service := services.NewPostService(repo)
+	service := services.NewPostService(repo, memory.NewCommentRepository(repo))
+
+	_, _, err := service.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 10, SortOrder: "INVALID"})
+	assert.Error(t, err)
+}
+
+func TestUpdatePost(t *testing.T) {
+	repo := memory.NewPostRepository()
+	service := services.NewPostService(repo, memory.NewCommentRepository(repo))
+
+	created, err := service.CreatePost("Title", "Content", "Author", true)
+	require.NoError(t, err)
+
+	newTitle := "New title"
+	updated, err := service.UpdatePost(created.ID, &newTitle, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "New title", updated.Title)
+	assert.Equal(t, "Content", updated.Content)
+
+	post, err := service.GetPost(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "New title", post.Title)
+
+	_, err = service.UpdatePost("non-existent-id", &newTitle, nil)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+func TestDeletePost_RemovesComments(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+
+	post, err := postService.CreatePost("Title", "Content", "Author", true)
+	require.NoError(t, err)
+	other, err := postService.CreatePost("Other", "Content", "Author", true)
+	require.NoError(t, err)
+
+	root, err := commentService.AddComment(post.ID, "User1", "Root", nil)
+	require.NoError(t, err)
+	_, err = commentService.AddComment(post.ID, "User2", "Reply", &root.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, postService.DeletePost(post.ID))
+
+	_, err = postService.GetPost(post.ID)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	count, err := commentService.GetCommentsCount(post.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	_, err = commentService.GetCommentsCount(post.ID, &root.ID)
+	assert.ErrorIs(t, err, repositories.ErrParentNotFound)
+
+	posts, _, err := postService.GetPosts(repositories.PostFilter{}, repositories.Page{Limit: 10, SortOrder: "DESC"})
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	assert.Equal(t, other.ID, posts[0].ID)
+
+	assert.ErrorIs(t, postService.DeletePost(post.ID), repositories.ErrNotFound)
+}
+
+func TestGetPostsByIDs(t *testing.T) {
+	memRepo := memory.NewPostRepository()
+	service := services.NewPostService(memRepo, memory.NewCommentRepository(memRepo))
+
+	first, err := service.CreatePost("Title 1", "Content", "Author", true)
+	require.NoError(t, err)
+	second, err := service.CreatePost("Title 2", "Content", "Author", true)
+	require.NoError(t, err)
+
+	posts, err := service.GetPostsByIDs([]string{first.ID, second.ID, "missing"})
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	assert.Equal(t, first.ID, posts[first.ID].ID)
+	assert.Equal(t, second.ID, posts[second.ID].ID)
+}
+
+func TestPostTagsAndCategory(t *testing.T) {
+	repo := memory.NewPostRepository()
+	service := services.NewPostService(repo, memory.NewCommentRepository(repo))
+
+	var posts []*models.Post
+	for _, title := range []string{"Title 1", "Title 2", "Title 3", "Title 4"} {
+		post, err := service.CreatePost(title, "Content", "Author", true)
+		require.NoError(t, err)
+		posts = append(posts, post)
+		time.Sleep(time.Millisecond)
+	}
 
-	_, err := service.GetPosts(10, nil, "INVALID")
+	tagged, err := service.SetTags(posts[0].ID, []string{"Go", "graphql", " go "})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "graphql"}, tagged.Tags)
+	_, err = service.SetTags(posts[1].ID, []string{"go"})
+	require.NoError(t, err)
+	_, err = service.SetTags(posts[2].ID, []string{"go", "golang"})
+	require.NoError(t, err)
+	_, err = service.SetTags(posts[3].ID, []string{"rust"})
+	require.NoError(t, err)
+
+	_, err = service.SetTags(posts[3].ID, []string{"no spaces"})
 	assert.Error(t, err)
+	_, err = service.SetTags("missing", []string{"go"})
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	news := " News "
+	categorized, err := service.SetCategory(posts[0].ID, &news)
+	require.NoError(t, err)
+	require.NotNil(t, categorized.Category)
+	assert.Equal(t, "News", *categorized.Category)
+	_, err = service.SetCategory(posts[2].ID, &news)
+	require.NoError(t, err)
+	_, err = service.SetCategory(posts[3].ID, &news)
+	require.NoError(t, err)
+
+	// A blank category removes the post from its category.
+	blank := " "
+	uncategorized, err := service.SetCategory(posts[3].ID, &blank)
+	require.NoError(t, err)
+	assert.Nil(t, uncategorized.Category)
+
+	filter := repositories.PostFilter{Tag: "GO"}
+	page, hasMore, err := service.GetPosts(filter, repositories.Page{Limit: 2, SortOrder: "DESC"})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.True(t, hasMore)
+	assert.Equal(t, posts[2].ID, page[0].ID)
+	assert.Equal(t, posts[1].ID, page[1].ID)
+
+	page, hasMore, err = service.GetPosts(filter, repositories.Page{Limit: 2, After: repositories.PostCursor(page[1]), SortOrder: "DESC"})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.False(t, hasMore)
+	assert.Equal(t, posts[0].ID, page[0].ID)
+
+	count, err := service.GetPostsCount(filter)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	filter = repositories.PostFilter{Tag: "go", Category: "News"}
+	page, _, err = service.GetPosts(filter, repositories.Page{Limit: 10, SortOrder: "ASC"})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, posts[0].ID, page[0].ID)
+	assert.Equal(t, posts[2].ID, page[1].ID)
+
+	tags, err := service.GetTags("G", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.TagCount{
+		{Tag: "go", Count: 3},
+		{Tag: "golang", Count: 1},
+		{Tag: "graphql", Count: 1},
+	}, tags)
+
+	tags, err = service.GetTags("", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.TagCount{{Tag: "go", Count: 3}}, tags)
 }
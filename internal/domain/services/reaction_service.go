@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+// ReactionService manages emoji reactions on comments. Only the configured
+// emoji may be added; any reaction can be removed, so narrowing the set
+// does not strand existing ones.
+type ReactionService struct {
+	repo        repositories.ReactionRepository
+	commentRepo repositories.CommentRepository
+	postRepo    repositories.PostRepository
+	allowed     map[string]struct{}
+}
+
+// NewReactionService allows the listed emoji; surrounding spaces are ignored
+// and blank entries dropped, so a list split from a flag can be passed as is.
+func NewReactionService(repo repositories.ReactionRepository, commentRepo repositories.CommentRepository, postRepo repositories.PostRepository, allowedEmoji []string) *ReactionService {
+	allowed := make(map[string]struct{}, len(allowedEmoji))
+	for _, emoji := range allowedEmoji {
+		if emoji = strings.TrimSpace(emoji); emoji != "" {
+			allowed[emoji] = struct{}{}
+		}
+	}
+
+	return &ReactionService{
+		repo:        repo,
+		commentRepo: commentRepo,
+		postRepo:    postRepo,
+		allowed:     allowed,
+	}
+}
+
+// AddReaction adds the reaction of the user and returns the comment.
+// Reacting twice with the same emoji is a no-op.
+func (s *ReactionService) AddReaction(commentID, emoji, user string) (*models.Comment, error) {
+	if user == "" {
+		return nil, errors.New("user must not be empty")
+	}
+	if _, ok := s.allowed[emoji]; !ok {
+		return nil, errors.New("emoji is not allowed")
+	}
+
+	comment, err := s.writableComment(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Deleted {
+		return nil, repositories.ErrCommentDeleted
+	}
+
+	if err := s.repo.Add(commentID, emoji, user); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// RemoveReaction withdraws the reaction of the user and returns the comment.
+func (s *ReactionService) RemoveReaction(commentID, emoji, user string) (*models.Comment, error) {
+	comment, err := s.writableComment(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Remove(commentID, emoji, user); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// writableComment loads a comment whose post is not archived.
+func (s *ReactionService) writableComment(id string) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.postRepo.GetByID(comment.PostID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureWritable(post); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetReactions returns the reaction summaries of the comments, marking the
+// ones the user has reacted with.
+func (s *ReactionService) GetReactions(commentIDs []string, user string) (map[string][]*models.ReactionSummary, error) {
+	return s.repo.GetSummaries(commentIDs, user)
+}
@@ -0,0 +1,132 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/services"
+	"posts_comments_service/internal/repository/memory"
+)
+
+func TestReactions(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	reactionService := services.NewReactionService(memory.NewReactionRepository(), commentRepo, postRepo, []string{"👍", "🎉"})
+
+	post, err := postService.CreatePost("Post", "Content", "Author", true)
+	require.NoError(t, err)
+	first, err := commentService.AddComment(post.ID, "Author", "first", nil)
+	require.NoError(t, err)
+	second, err := commentService.AddComment(post.ID, "Author", "second", nil)
+	require.NoError(t, err)
+
+	_, err = reactionService.AddReaction(first.ID, "🎉", "alice")
+	require.NoError(t, err)
+	_, err = reactionService.AddReaction(first.ID, "👍", "alice")
+	require.NoError(t, err)
+	_, err = reactionService.AddReaction(first.ID, "👍", "bob")
+	require.NoError(t, err)
+	// Reacting twice is a no-op.
+	_, err = reactionService.AddReaction(first.ID, "👍", "bob")
+	require.NoError(t, err)
+
+	reactions, err := reactionService.GetReactions([]string{first.ID, second.ID}, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ReactionSummary{
+		{Emoji: "👍", Count: 2, ReactedByMe: true},
+		{Emoji: "🎉", Count: 1, ReactedByMe: false},
+	}, reactions[first.ID])
+	assert.Empty(t, reactions[second.ID])
+
+	_, err = reactionService.RemoveReaction(first.ID, "👍", "bob")
+	require.NoError(t, err)
+	_, err = reactionService.RemoveReaction(first.ID, "🎉", "alice")
+	require.NoError(t, err)
+	// Removing a missing reaction is a no-op.
+	_, err = reactionService.RemoveReaction(first.ID, "🎉", "alice")
+	require.NoError(t, err)
+
+	reactions, err = reactionService.GetReactions([]string{first.ID}, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ReactionSummary{
+		{Emoji: "👍", Count: 1, ReactedByMe: false},
+	}, reactions[first.ID])
+}
+
+func TestReactions_DeletedWithPost(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	reactionRepo := memory.NewReactionRepository()
+	commentRepo := memory.NewCommentRepository(postRepo, reactionRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	reactionService := services.NewReactionService(reactionRepo, commentRepo, postRepo, []string{"👍"})
+
+	post, err := postService.CreatePost("Post", "Content", "Author", true)
+	require.NoError(t, err)
+	comment, err := commentService.AddComment(post.ID, "Author", "text", nil)
+	require.NoError(t, err)
+	_, err = reactionService.AddReaction(comment.ID, "👍", "alice")
+	require.NoError(t, err)
+
+	require.NoError(t, postService.DeletePost(post.ID))
+
+	reactions, err := reactionService.GetReactions([]string{comment.ID}, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, reactions[comment.ID])
+}
+
+func TestAddReaction_Validation(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	reactionService := services.NewReactionService(memory.NewReactionRepository(), commentRepo, postRepo, []string{"👍"})
+
+	post, err := postService.CreatePost("Post", "Content", "Author", true)
+	require.NoError(t, err)
+	comment, err := commentService.AddComment(post.ID, "Author", "text", nil)
+	require.NoError(t, err)
+
+	_, err = reactionService.AddReaction(comment.ID, "🐍", "alice")
+	assert.EqualError(t, err, "emoji is not allowed")
+
+	_, err = reactionService.AddReaction(comment.ID, "👍", "")
+	assert.Error(t, err)
+
+	_, err = reactionService.AddReaction("missing", "👍", "alice")
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	require.NoError(t, commentService.DeleteComment(comment.ID))
+	_, err = reactionService.AddReaction(comment.ID, "👍", "alice")
+	assert.ErrorIs(t, err, repositories.ErrCommentDeleted)
+}
+
+func TestReactions_AllowedEmojiTrimmed(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	reactionService := services.NewReactionService(memory.NewReactionRepository(), commentRepo, postRepo, strings.Split("👍, ❤️,", ","))
+
+	post, err := postService.CreatePost("Post", "Content", "Author", true)
+	require.NoError(t, err)
+	comment, err := commentService.AddComment(post.ID, "Author", "text", nil)
+	require.NoError(t, err)
+
+	_, err = reactionService.AddReaction(comment.ID, "❤️", "alice")
+	assert.NoError(t, err)
+	_, err = reactionService.AddReaction(comment.ID, " ❤️", "alice")
+	assert.Error(t, err)
+	_, err = reactionService.AddReaction(comment.ID, "", "alice")
+	assert.Error(t, err)
+}
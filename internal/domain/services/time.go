@@ -0,0 +1,17 @@
+package services
+
+import (
+	"time"
+
+	"posts_comments_service/internal/domain/constants"
+)
+
+// now returns the current time in the precision the repositories can store,
+// so that a freshly created entity sorts exactly like its stored copy.
+func now() string {
+	return currentTime().Format(constants.TimeFormat)
+}
+
+func currentTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
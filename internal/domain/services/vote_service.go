@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+// VoteService records up- and downvotes on posts and comments. The counters
+// live with their targets, so each repository applies the votes on its own
+// rows.
+type VoteService struct {
+	postRepo    repositories.PostRepository
+	commentRepo repositories.CommentRepository
+}
+
+func NewVoteService(postRepo repositories.PostRepository, commentRepo repositories.CommentRepository) *VoteService {
+	return &VoteService{
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+	}
+}
+
+// Vote sets the vote of the voter on a comment or post: 1 up, -1 down and
+// 0 to withdraw it. Voting again replaces the earlier vote.
+func (s *VoteService) Vote(targetID, voter string, value int) (*models.VoteTally, error) {
+	if value < -1 || value > 1 {
+		return nil, errors.New("vote value must be -1, 0 or 1")
+	}
+	if voter == "" {
+		return nil, errors.New("voter must not be empty")
+	}
+
+	// Votes on an archived post or on its comments are rejected.
+	comment, err := s.commentRepo.GetByID(targetID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		if err := s.ensureWritable(targetID); err != nil {
+			return nil, err
+		}
+		return s.postRepo.Vote(targetID, voter, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureWritable(comment.PostID); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.Vote(targetID, voter, value)
+}
+
+func (s *VoteService) ensureWritable(postID string) error {
+	post, err := s.postRepo.GetByID(postID)
+	if err != nil {
+		return err
+	}
+	return ensureWritable(post)
+}
+
+// GetCommentVotes and GetPostVotes return the votes of the voter on the
+// given targets; targets without a vote are missing from the map.
+func (s *VoteService) GetCommentVotes(voter string, ids []string) (map[string]int, error) {
+	return s.commentRepo.GetVotes(voter, ids)
+}
+
+func (s *VoteService) GetPostVotes(voter string, ids []string) (map[string]int, error) {
+	return s.postRepo.GetVotes(voter, ids)
+}
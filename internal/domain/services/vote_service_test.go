@@ -0,0 +1,138 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/services"
+	"posts_comments_service/internal/repository/memory"
+)
+
+func TestVote_Comment(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	voteService := services.NewVoteService(postRepo, commentRepo)
+
+	post, err := postService.CreatePost("Post", "Content", "Author", true)
+	require.NoError(t, err)
+	comment, err := commentService.AddComment(post.ID, "Author", "Comment", nil)
+	require.NoError(t, err)
+
+	tally, err := voteService.Vote(comment.ID, "alice", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, tally.Score)
+	assert.Equal(t, 1, tally.Value)
+
+	tally, err = voteService.Vote(comment.ID, "bob", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, tally.Score)
+
+	// Voting again replaces the earlier vote.
+	tally, err = voteService.Vote(comment.ID, "alice", -1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, tally.Score)
+	assert.Equal(t, 1, tally.Upvotes)
+	assert.Equal(t, 1, tally.Downvotes)
+
+	// Repeating the same vote changes nothing.
+	tally, err = voteService.Vote(comment.ID, "alice", -1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, tally.Score)
+
+	tally, err = voteService.Vote(comment.ID, "bob", 0)
+	require.NoError(t, err)
+	assert.Equal(t, -1, tally.Score)
+	assert.Equal(t, 0, tally.Upvotes)
+	assert.Equal(t, 0, tally.Value)
+
+	stored, err := commentService.GetComment(comment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, -1, stored.Score)
+	assert.Equal(t, 1, stored.Downvotes)
+
+	votes, err := voteService.GetCommentVotes("alice", []string{comment.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{comment.ID: -1}, votes)
+
+	votes, err = voteService.GetCommentVotes("bob", []string{comment.ID})
+	require.NoError(t, err)
+	assert.Empty(t, votes)
+}
+
+func TestVote_Post(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	voteService := services.NewVoteService(postRepo, commentRepo)
+
+	post, err := postService.CreatePost("Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	tally, err := voteService.Vote(post.ID, "alice", -1)
+	require.NoError(t, err)
+	assert.Equal(t, post.ID, tally.TargetID)
+	assert.Equal(t, -1, tally.Score)
+
+	stored, err := postService.GetPost(post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, -1, stored.Score)
+	assert.Equal(t, 1, stored.Downvotes)
+
+	votes, err := voteService.GetPostVotes("alice", []string{post.ID})
+	require.NoError(t, err)
+	assert.Equal(t, -1, votes[post.ID])
+}
+
+func TestVote_Validation(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	voteService := services.NewVoteService(postRepo, commentRepo)
+
+	post, err := postService.CreatePost("Post", "Content", "Author", true)
+	require.NoError(t, err)
+
+	_, err = voteService.Vote(post.ID, "alice", 2)
+	assert.Error(t, err)
+
+	_, err = voteService.Vote(post.ID, "", 1)
+	assert.Error(t, err)
+
+	_, err = voteService.Vote("missing", "alice", 1)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+}
+
+func TestVote_TopSortOrder(t *testing.T) {
+	postRepo := memory.NewPostRepository()
+	commentRepo := memory.NewCommentRepository(postRepo)
+
+	postService := services.NewPostService(postRepo, commentRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, services.ThreadLimits{})
+	voteService := services.NewVoteService(postRepo, commentRepo)
+
+	post, err := postService.CreatePost("Post", "Content", "Author", true)
+	require.NoError(t, err)
+	first, err := commentService.AddComment(post.ID, "Author", "first", nil)
+	require.NoError(t, err)
+	second, err := commentService.AddComment(post.ID, "Author", "second", nil)
+	require.NoError(t, err)
+
+	_, err = voteService.Vote(second.ID, "alice", 1)
+	require.NoError(t, err)
+	_, err = voteService.Vote(first.ID, "alice", -1)
+	require.NoError(t, err)
+
+	comments, _, err := commentService.GetComments(post.ID, nil, repositories.Page{Limit: 10, SortOrder: constants.SortTop})
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, second.ID, comments[0].ID)
+	assert.Equal(t, first.ID, comments[1].ID)
+}
@@ -0,0 +1,51 @@
+package tags
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"posts_comments_service/internal/domain/constants"
+)
+
+// tag matches the characters a tag may consist of.
+var tag = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+var (
+	ErrInvalidTag  = errors.New("tags may contain only letters, digits, '-' and '_'")
+	ErrTagTooLong  = errors.New("tag exceeds the 32 character limit")
+	ErrTooManyTags = errors.New("a post can have at most 10 tags")
+)
+
+// Normalize trims and lowercases the tags and returns them sorted, each
+// once. Blank tags are dropped.
+func Normalize(raw []string) ([]string, error) {
+	result := make([]string, 0, len(raw))
+	for _, t := range raw {
+		t = Canonical(t)
+		if t == "" {
+			continue
+		}
+		if utf8.RuneCountInString(t) > constants.MaxTagLength {
+			return nil, ErrTagTooLong
+		}
+		if !tag.MatchString(t) {
+			return nil, ErrInvalidTag
+		}
+		result = append(result, t)
+	}
+
+	slices.Sort(result)
+	result = slices.Compact(result)
+	if len(result) > constants.MaxPostTags {
+		return nil, ErrTooManyTags
+	}
+	return result, nil
+}
+
+// Canonical returns the stored form of a tag, also used to look tags up.
+func Canonical(t string) string {
+	return strings.ToLower(strings.TrimSpace(t))
+}
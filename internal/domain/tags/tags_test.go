@@ -0,0 +1,42 @@
+package tags_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/tags"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw  []string
+		want []string
+	}{
+		{nil, []string{}},
+		{[]string{"Go", " graphql ", "go"}, []string{"go", "graphql"}},
+		{[]string{"", "  "}, []string{}},
+		{[]string{"Новости", "web-dev", "c_sharp"}, []string{"c_sharp", "web-dev", "новости"}},
+	}
+
+	for _, tt := range tests {
+		got, err := tags.Normalize(tt.raw)
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+}
+
+func TestNormalize_Invalid(t *testing.T) {
+	_, err := tags.Normalize([]string{"two words"})
+	assert.ErrorIs(t, err, tags.ErrInvalidTag)
+
+	_, err = tags.Normalize([]string{"a:b"})
+	assert.ErrorIs(t, err, tags.ErrInvalidTag)
+
+	_, err = tags.Normalize([]string{strings.Repeat("x", 33)})
+	assert.ErrorIs(t, err, tags.ErrTagTooLong)
+
+	_, err = tags.Normalize([]string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"})
+	assert.ErrorIs(t, err, tags.ErrTooManyTags)
+}
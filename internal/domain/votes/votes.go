@@ -0,0 +1,32 @@
+package votes
+
+import "posts_comments_service/internal/domain/models"
+
+// Deltas returns the change of the upvote and downvote counters when the
+// vote of a voter changes from previous to value; 0 means no vote.
+func Deltas(previous, value int) (int, int) {
+	return delta(previous, value, 1), delta(previous, value, -1)
+}
+
+// delta is the change of the counter of votes equal to side.
+func delta(previous, value, side int) int {
+	d := 0
+	if previous == side {
+		d--
+	}
+	if value == side {
+		d++
+	}
+	return d
+}
+
+// Tally reports the counters of a target after the voter cast value.
+func Tally(targetID string, upvotes, downvotes, value int) *models.VoteTally {
+	return &models.VoteTally{
+		TargetID:  targetID,
+		Score:     upvotes - downvotes,
+		Upvotes:   upvotes,
+		Downvotes: downvotes,
+		Value:     value,
+	}
+}
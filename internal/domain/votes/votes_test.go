@@ -0,0 +1,38 @@
+package votes_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"posts_comments_service/internal/domain/votes"
+)
+
+func TestDeltas(t *testing.T) {
+	tests := []struct {
+		previous, value int
+		up, down        int
+	}{
+		{0, 1, 1, 0},
+		{0, -1, 0, 1},
+		{1, 1, 0, 0},
+		{1, -1, -1, 1},
+		{-1, 1, 1, -1},
+		{1, 0, -1, 0},
+		{-1, 0, 0, -1},
+		{0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		up, down := votes.Deltas(tt.previous, tt.value)
+		assert.Equal(t, tt.up, up, "%d -> %d", tt.previous, tt.value)
+		assert.Equal(t, tt.down, down, "%d -> %d", tt.previous, tt.value)
+	}
+}
+
+func TestTally(t *testing.T) {
+	tally := votes.Tally("id", 5, 2, -1)
+	assert.Equal(t, 3, tally.Score)
+	assert.Equal(t, 5, tally.Upvotes)
+	assert.Equal(t, 2, tally.Downvotes)
+	assert.Equal(t, -1, tally.Value)
+}
@@ -3,43 +3,49 @@ package memory
 import (
 	"posts_comments_service/internal/domain/constants"
 	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/ranking"
 	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/votes"
+	"slices"
 	"sync"
+	"time"
 )
 
 type commentRepository struct {
 	mu           sync.RWMutex
 	comments     map[string]*models.Comment
 	commentsTree map[string]*commentLevel
-	postRepo     repositories.PostRepository
+	revisions    map[string][]*models.CommentRevision
+	votes        voteBook
+	// mentions maps a user to the ids of the comments mentioning them.
+	mentions   map[string]map[string]struct{}
+	postRepo   PostRepository
+	dependents []CommentDependent
 }
 
-func (r *commentRepository) CountReplies(postID string) (map[string]int, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	counts := make(map[string]int)
-
-	for _, comment := range r.comments {
-		if comment.PostID != postID || comment.ParentID == nil {
-			continue
-		}
-		counts[*comment.ParentID]++
-	}
-
-	return counts, nil
+// CommentDependent keeps data about comments. The memory store has no
+// foreign keys, so the comment repository tells its dependents which
+// comments it deleted together with a post.
+type CommentDependent interface {
+	CommentsDeleted(postID string, commentIDs []string)
 }
 
 type commentLevel struct {
 	comments []*models.Comment
 	indexMap map[string]int
+	// pinned counts the pinned comments; only top-level comments are pinned.
+	pinned int
 }
 
-func NewCommentRepository(postRepo repositories.PostRepository) repositories.CommentRepository {
+func NewCommentRepository(postRepo PostRepository, dependents ...CommentDependent) repositories.CommentRepository {
 	return &commentRepository{
 		comments:     make(map[string]*models.Comment),
 		commentsTree: make(map[string]*commentLevel),
+		revisions:    make(map[string][]*models.CommentRevision),
+		votes:        make(voteBook),
+		mentions:     make(map[string]map[string]struct{}),
 		postRepo:     postRepo,
+		dependents:   dependents,
 	}
 }
 
@@ -56,14 +62,33 @@ func (r *commentRepository) Create(comment *models.Comment) error {
 		return repositories.ErrNotFound
 	}
 
+	if post.ArchivedAt != nil {
+		return repositories.ErrPostArchived
+	}
 	if !post.AllowComments {
 		return repositories.ErrCommentsDisabled
 	}
 
+	var parent *models.Comment
 	if comment.ParentID != nil {
-		if parent, exists := r.comments[*comment.ParentID]; !exists || parent.PostID != comment.PostID {
+		var exists bool
+		parent, exists = r.comments[*comment.ParentID]
+		if !exists || parent.PostID != comment.PostID {
 			return repositories.ErrParentNotFound
 		}
+		if parent.Deleted {
+			return repositories.ErrCommentDeleted
+		}
+		for ancestor := parent; ancestor != nil; ancestor = r.parentOf(ancestor) {
+			if ancestor.Locked {
+				return repositories.ErrThreadLocked
+			}
+		}
+	}
+
+	comment.Depth = 1
+	if parent != nil {
+		comment.Depth = parent.Depth + 1
 	}
 
 	levelKey := comment.PostID
@@ -82,73 +107,451 @@ func (r *commentRepository) Create(comment *models.Comment) error {
 	level.indexMap[comment.ID] = len(level.comments)
 	level.comments = append(level.comments, comment)
 	r.comments[comment.ID] = comment
+	r.indexMentions(comment.ID, nil, comment.Mentions)
+
+	for ancestor := parent; ancestor != nil; {
+		updated := *ancestor
+		if ancestor == parent {
+			updated.RepliesCount++
+		}
+		updated.DescendantsCount++
+		if laterThan(comment.CreatedAt, updated.LastActivityAt) {
+			updated.LastActivityAt = comment.CreatedAt
+		}
+		r.replace(&updated)
+
+		ancestor = r.parentOf(&updated)
+	}
+
+	r.postRepo.AddComments(comment.PostID, 1)
 
 	return nil
 }
 
-func (r *commentRepository) GetByPostID(postID string, parentID *string, limit int, after *string, sortOrder string) ([]*models.Comment, bool, error) {
+// parentOf returns the parent of a comment, or nil for a top-level one.
+func (r *commentRepository) parentOf(comment *models.Comment) *models.Comment {
+	if comment.ParentID == nil {
+		return nil
+	}
+	return r.comments[*comment.ParentID]
+}
+
+func (r *commentRepository) GetByID(id string) (*models.Comment, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if limit <= 0 {
-		return []*models.Comment{}, false, nil
+	comment, exists := r.comments[id]
+	if !exists {
+		return nil, repositories.ErrNotFound
+	}
+	return comment, nil
+}
+
+func (r *commentRepository) GetAncestors(id string) ([]*models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, exists := r.comments[id]
+	if !exists {
+		return nil, repositories.ErrNotFound
+	}
+
+	ancestors := make([]*models.Comment, 0)
+	for comment.ParentID != nil {
+		comment = r.comments[*comment.ParentID]
+		ancestors = append(ancestors, comment)
 	}
+	slices.Reverse(ancestors)
+
+	return ancestors, nil
+}
+
+func (r *commentRepository) GetByPostID(postID string, parentID *string, page repositories.Page) ([]*models.Comment, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	levelKey := postID
 	if parentID != nil {
 		levelKey = *parentID
 	}
 
+	return r.levelPage(levelKey, page)
+}
+
+func (r *commentRepository) GetRepliesPages(parentIDs []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error) {
+	return r.firstPages(parentIDs, limit, sortOrder)
+}
+
+func (r *commentRepository) GetTopLevelPages(postIDs []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error) {
+	return r.firstPages(postIDs, limit, sortOrder)
+}
+
+// firstPages pages several levels of the tree at once; a level is keyed by
+// the parent comment ID, or by the post ID for top-level comments.
+func (r *commentRepository) firstPages(levelKeys []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := repositories.Page{Limit: limit, SortOrder: sortOrder}
+	result := make(map[string]*repositories.CommentPage, len(levelKeys))
+	for _, levelKey := range levelKeys {
+		comments, hasMore, err := r.levelPage(levelKey, page)
+		if err != nil {
+			return nil, err
+		}
+
+		totalCount := 0
+		if level, exists := r.commentsTree[levelKey]; exists {
+			totalCount = len(level.comments)
+		}
+
+		result[levelKey] = &repositories.CommentPage{
+			Comments:   comments,
+			HasMore:    hasMore,
+			TotalCount: totalCount,
+		}
+	}
+
+	return result, nil
+}
+
+// levelPage must be called with r.mu held.
+func (r *commentRepository) levelPage(levelKey string, page repositories.Page) ([]*models.Comment, bool, error) {
+	if page.Limit <= 0 {
+		return []*models.Comment{}, false, nil
+	}
+
 	level, exists := r.commentsTree[levelKey]
 	if !exists {
 		return nil, false, nil
 	}
 
-	total := len(level.comments)
-	if total == 0 {
-		return nil, false, nil
+	if level.pinned > 0 || page.After != nil && page.After.Pinned || page.Before != nil && page.Before.Pinned {
+		return repositories.PinnedFirst(page, func(pinned bool, segment repositories.Page) ([]*models.Comment, bool, error) {
+			comments := slices.DeleteFunc(slices.Clone(level.comments), func(c *models.Comment) bool {
+				return (c.PinnedAt != nil) != pinned
+			})
+			compare := compareCommentKeys(segment.SortOrder)
+			if pinned {
+				compare = comparePinned
+			}
+			result, hasMore := sortedPage(comments, repositories.CommentCursor, compare, segment)
+			return result, hasMore, nil
+		})
 	}
 
-	if sortOrder == constants.SortDesc {
-		start := total - 1
-		if after != nil {
-			if idx, ok := level.indexMap[*after]; ok {
-				start = idx - 1
-			} else {
-				return nil, false, repositories.ErrInvalidCursor
-			}
+	result, hasMore := sortedPage(level.comments, repositories.CommentCursor, compareCommentKeys(page.SortOrder), page)
+	return result, hasMore, nil
+}
+
+func (r *commentRepository) GetTree(postID string, rootID *string, maxDepth, maxChildren int) ([]*models.CommentNode, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		roots   []*models.Comment
+		hasMore bool
+	)
+	if rootID != nil {
+		root, exists := r.comments[*rootID]
+		if !exists || root.PostID != postID {
+			return nil, false, repositories.ErrNotFound
+		}
+		roots = []*models.Comment{root}
+	} else {
+		var err error
+		roots, hasMore, err = r.levelPage(postID, repositories.Page{Limit: maxChildren, SortOrder: constants.SortAsc})
+		if err != nil {
+			return nil, false, err
 		}
+	}
 
-		end := start - limit + 1
-		if end < 0 {
-			end = -1
+	nodes := make([]*models.CommentNode, len(roots))
+	for i, root := range roots {
+		nodes[i] = r.subtree(root, maxDepth-1, maxChildren)
+	}
+	return nodes, hasMore, nil
+}
+
+// subtree must be called with r.mu held.
+func (r *commentRepository) subtree(comment *models.Comment, depth, maxChildren int) *models.CommentNode {
+	node := &models.CommentNode{Comment: comment, Children: []*models.CommentNode{}}
+	if depth > 0 {
+		children, _, _ := r.levelPage(comment.ID, repositories.Page{Limit: maxChildren, SortOrder: constants.SortAsc})
+		for _, child := range children {
+			node.Children = append(node.Children, r.subtree(child, depth-1, maxChildren))
 		}
+	}
+	node.HasMoreChildren = comment.RepliesCount > len(node.Children)
+	return node
+}
 
-		result := make([]*models.Comment, 0, limit)
-		for i := start; i > end; i-- {
-			result = append(result, level.comments[i])
-			if len(result) >= limit {
-				break
-			}
+func (r *commentRepository) Edit(id string, text string, mentions []string, editedAt string) (*models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(text) > constants.MaxCommentLength {
+		return nil, repositories.ErrTextTooLong
+	}
+
+	existing, ok := r.comments[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	if existing.Deleted {
+		return nil, repositories.ErrCommentDeleted
+	}
+
+	previousAt := existing.CreatedAt
+	if existing.EditedAt != nil {
+		previousAt = *existing.EditedAt
+	}
+	r.revisions[id] = append(r.revisions[id], &models.CommentRevision{
+		CommentID: id,
+		Text:      existing.Text,
+		CreatedAt: previousAt,
+	})
+
+	updated := *existing
+	updated.Text = text
+	updated.Mentions = mentions
+	updated.EditedAt = &editedAt
+	r.replace(&updated)
+	r.indexMentions(id, existing.Mentions, mentions)
+
+	return &updated, nil
+}
+
+func (r *commentRepository) GetRevisions(commentID string) ([]*models.CommentRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := make([]*models.CommentRevision, len(r.revisions[commentID]))
+	copy(revisions, r.revisions[commentID])
+	return revisions, nil
+}
+
+func (r *commentRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.comments[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+
+	tombstone := *existing
+	tombstone.Text = ""
+	tombstone.Author = ""
+	tombstone.EditedAt = nil
+	tombstone.Deleted = true
+	tombstone.Mentions = nil
+	r.indexMentions(id, existing.Mentions, nil)
+	if existing.PinnedAt != nil {
+		tombstone.PinnedAt = nil
+		r.commentsTree[existing.PostID].pinned--
+	}
+	r.replace(&tombstone)
+	delete(r.revisions, id)
+
+	return nil
+}
+
+func (r *commentRepository) Pin(id string, pinnedAt string, maxPinned int) (*models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.comments[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	if existing.PinnedAt != nil {
+		return existing, nil
+	}
+
+	level := r.commentsTree[existing.PostID]
+	if level.pinned >= maxPinned {
+		return nil, repositories.ErrPinLimitReached
+	}
+
+	updated := *existing
+	updated.PinnedAt = &pinnedAt
+	r.replace(&updated)
+	level.pinned++
+
+	return &updated, nil
+}
+
+func (r *commentRepository) Unpin(id string) (*models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.comments[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	if existing.PinnedAt == nil {
+		return existing, nil
+	}
+
+	updated := *existing
+	updated.PinnedAt = nil
+	r.replace(&updated)
+	r.commentsTree[existing.PostID].pinned--
+
+	return &updated, nil
+}
+
+func (r *commentRepository) SetLocked(id string, locked bool) (*models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.comments[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+
+	updated := *existing
+	updated.Locked = locked
+	r.replace(&updated)
+
+	return &updated, nil
+}
+
+func (r *commentRepository) GetMentioning(user string, page repositories.Page) ([]*models.Comment, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := make([]*models.Comment, 0, len(r.mentions[user]))
+	for id := range r.mentions[user] {
+		comments = append(comments, r.comments[id])
+	}
+
+	result, hasMore := sortedPage(comments, repositories.CommentCursor, compareCommentKeys(constants.SortDesc), page)
+	return result, hasMore, nil
+}
+
+func (r *commentRepository) CountMentioning(user string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.mentions[user]), nil
+}
+
+// indexMentions moves a comment from the index entries of the users it
+// mentioned to those it mentions now. It must be called with r.mu held.
+func (r *commentRepository) indexMentions(id string, previous, current []string) {
+	for _, user := range previous {
+		delete(r.mentions[user], id)
+		if len(r.mentions[user]) == 0 {
+			delete(r.mentions, user)
+		}
+	}
+	for _, user := range current {
+		if r.mentions[user] == nil {
+			r.mentions[user] = make(map[string]struct{})
 		}
-		return result, start-limit >= 0, nil
+		r.mentions[user][id] = struct{}{}
+	}
+}
+
+func (r *commentRepository) Vote(id string, voter string, value int) (*models.VoteTally, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.comments[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
 	}
+	if existing.Deleted {
+		return nil, repositories.ErrCommentDeleted
+	}
+
+	up, down := r.votes.cast(id, voter, value)
+
+	updated := *existing
+	updated.Upvotes += up
+	updated.Downvotes += down
+	updated.Score = updated.Upvotes - updated.Downvotes
+	createdAt, _ := time.Parse(constants.TimeFormat, updated.CreatedAt)
+	updated.Hot = ranking.Hot(updated.Score, createdAt)
+	r.replace(&updated)
 
-	start := 0
-	if after != nil {
-		if idx, ok := level.indexMap[*after]; ok {
-			start = idx + 1
-		} else {
-			return nil, false, repositories.ErrInvalidCursor
+	return votes.Tally(id, updated.Upvotes, updated.Downvotes, value), nil
+}
+
+func (r *commentRepository) GetVotes(voter string, ids []string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.votes.get(voter, ids), nil
+}
+
+func laterThan(a, b string) bool {
+	at, _ := time.Parse(constants.TimeFormat, a)
+	bt, _ := time.Parse(constants.TimeFormat, b)
+	return at.After(bt)
+}
+
+// replace swaps the stored comment for an updated copy. Stored comments are
+// never mutated in place, so values already handed out to readers stay intact.
+func (r *commentRepository) replace(comment *models.Comment) {
+	levelKey := comment.PostID
+	if comment.ParentID != nil {
+		levelKey = *comment.ParentID
+	}
+
+	level := r.commentsTree[levelKey]
+	level.comments[level.indexMap[comment.ID]] = comment
+	r.comments[comment.ID] = comment
+}
+
+func (r *commentRepository) DeleteByPostID(postID string) error {
+	deleted := r.deleteByPostID(postID)
+	for _, dependent := range r.dependents {
+		dependent.CommentsDeleted(postID, deleted)
+	}
+	return nil
+}
+
+func (r *commentRepository) deleteByPostID(postID string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted []string
+	for id, comment := range r.comments {
+		if comment.PostID != postID {
+			continue
 		}
+		delete(r.comments, id)
+		delete(r.commentsTree, id)
+		delete(r.revisions, id)
+		delete(r.votes, id)
+		r.indexMentions(id, comment.Mentions, nil)
+		deleted = append(deleted, id)
 	}
+	delete(r.commentsTree, postID)
+
+	return deleted
+}
 
-	end := start + limit
-	if end > total {
-		end = total
+func (r *commentRepository) CountDescendants(id string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, exists := r.comments[id]
+	if !exists {
+		return 0, repositories.ErrNotFound
 	}
+	return comment.DescendantsCount, nil
+}
 
-	return level.comments[start:end], end < total, nil
+func (r *commentRepository) CountAll(postID string) (int, error) {
+	post, err := r.postRepo.GetByID(postID)
+	if err != nil {
+		return 0, err
+	}
+	return post.CommentsTotal, nil
 }
 
 func (r *commentRepository) Count(postID string, parentID *string) (int, error) {
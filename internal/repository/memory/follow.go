@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"posts_comments_service/internal/domain/repositories"
+)
+
+type followRepository struct {
+	mu sync.RWMutex
+	// posts and threads map a post or comment id to its followers.
+	posts   map[string]map[string]struct{}
+	threads map[string]map[string]struct{}
+}
+
+// FollowRepository is a follow repository that drops the follows of the
+// posts and threads deleted from the memory store.
+type FollowRepository interface {
+	repositories.FollowRepository
+	CommentDependent
+}
+
+func NewFollowRepository() FollowRepository {
+	return &followRepository{
+		posts:   make(map[string]map[string]struct{}),
+		threads: make(map[string]map[string]struct{}),
+	}
+}
+
+func (r *followRepository) FollowPost(postID, user string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	follow(r.posts, postID, user)
+	return nil
+}
+
+func (r *followRepository) UnfollowPost(postID, user string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unfollow(r.posts, postID, user)
+	return nil
+}
+
+func (r *followRepository) FollowThread(commentID, user string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	follow(r.threads, commentID, user)
+	return nil
+}
+
+func (r *followRepository) UnfollowThread(commentID, user string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unfollow(r.threads, commentID, user)
+	return nil
+}
+
+func (r *followRepository) CountPostFollowers(postIDs []string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]int)
+	for _, id := range postIDs {
+		if followers := len(r.posts[id]); followers > 0 {
+			result[id] = followers
+		}
+	}
+	return result, nil
+}
+
+func (r *followRepository) GetFollowedPosts(user string, postIDs []string) (map[string]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]bool)
+	for _, id := range postIDs {
+		if _, ok := r.posts[id][user]; ok {
+			result[id] = true
+		}
+	}
+	return result, nil
+}
+
+func (r *followRepository) GetPostFollowers(postID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return followersOf(r.posts, []string{postID}), nil
+}
+
+func (r *followRepository) GetThreadFollowers(commentIDs []string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return followersOf(r.threads, commentIDs), nil
+}
+
+func follow(followers map[string]map[string]struct{}, id, user string) {
+	users, exists := followers[id]
+	if !exists {
+		users = make(map[string]struct{})
+		followers[id] = users
+	}
+	users[user] = struct{}{}
+}
+
+func unfollow(followers map[string]map[string]struct{}, id, user string) {
+	delete(followers[id], user)
+	if len(followers[id]) == 0 {
+		delete(followers, id)
+	}
+}
+
+// followersOf returns the followers of any of the ids, each once, sorted.
+func followersOf(followers map[string]map[string]struct{}, ids []string) []string {
+	seen := make(map[string]struct{})
+	var result []string
+	for _, id := range ids {
+		for user := range followers[id] {
+			if _, ok := seen[user]; !ok {
+				seen[user] = struct{}{}
+				result = append(result, user)
+			}
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+func (r *followRepository) CommentsDeleted(postID string, commentIDs []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.posts, postID)
+	for _, id := range commentIDs {
+		delete(r.threads, id)
+	}
+}
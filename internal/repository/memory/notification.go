@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+type notificationRepository struct {
+	mu sync.RWMutex
+	// inboxes holds the notifications of every recipient in creation order.
+	inboxes map[string][]*models.Notification
+}
+
+// NotificationRepository is a notification repository that drops the
+// notifications about the posts deleted from the memory store.
+type NotificationRepository interface {
+	repositories.NotificationRepository
+	CommentDependent
+}
+
+func NewNotificationRepository() NotificationRepository {
+	return &notificationRepository{
+		inboxes: make(map[string][]*models.Notification),
+	}
+}
+
+func (r *notificationRepository) Create(notifications []*models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, notification := range notifications {
+		r.inboxes[notification.Recipient] = append(r.inboxes[notification.Recipient], notification)
+	}
+	return nil
+}
+
+func (r *notificationRepository) List(recipient string, unreadOnly bool, page repositories.Page) ([]*models.Notification, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var notifications []*models.Notification
+	for _, notification := range r.inboxes[recipient] {
+		if !unreadOnly || !notification.Read {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	// Newest first, with the same order as the comments sorted by DESC.
+	result, hasMore := sortedPage(notifications, repositories.NotificationCursor, compareCommentKeys(constants.SortDesc), page)
+	return result, hasMore, nil
+}
+
+func (r *notificationRepository) CountUnread(recipient string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, notification := range r.inboxes[recipient] {
+		if !notification.Read {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *notificationRepository) MarkRead(recipient string, ids []string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	marked := 0
+	inbox := r.inboxes[recipient]
+	for i, notification := range inbox {
+		if notification.Read || !slices.Contains(ids, notification.ID) {
+			continue
+		}
+		updated := *notification
+		updated.Read = true
+		inbox[i] = &updated
+		marked++
+	}
+	return marked, nil
+}
+
+func (r *notificationRepository) CommentsDeleted(postID string, _ []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for recipient, inbox := range r.inboxes {
+		r.inboxes[recipient] = slices.DeleteFunc(inbox, func(n *models.Notification) bool {
+			return n.PostID == postID
+		})
+	}
+}
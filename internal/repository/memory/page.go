@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+
+	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+// keyedPage resolves a keyset page over items sorted by compare, which
+// orders two sort keys. Like the Postgres repository, it does not need the
+// cursor items to exist any more.
+func keyedPage[T any](sorted []T, keys []*repositories.Cursor, compare func(a, b *repositories.Cursor) int, page repositories.Page) ([]T, bool) {
+	lo, hi := 0, len(sorted)
+	if page.After != nil {
+		lo = sort.Search(len(sorted), func(i int) bool { return compare(keys[i], page.After) > 0 })
+	}
+	if page.Before != nil {
+		hi = sort.Search(len(sorted), func(i int) bool { return compare(keys[i], page.Before) >= 0 })
+	}
+	if hi < lo || page.Limit <= 0 {
+		return nil, false
+	}
+
+	if page.Backward {
+		from := max(hi-page.Limit, lo)
+		return sorted[from:hi], from > lo
+	}
+
+	to := min(lo+page.Limit, hi)
+	return sorted[lo:to], to < hi
+}
+
+// sortedPage sorts a copy of the items by the compare of their keys and
+// pages it by key.
+func sortedPage[T any](items []T, key func(T) *repositories.Cursor, compare func(a, b *repositories.Cursor) int, page repositories.Page) ([]T, bool) {
+	type keyed struct {
+		item T
+		key  *repositories.Cursor
+	}
+	entries := make([]keyed, len(items))
+	for i, item := range items {
+		entries[i] = keyed{item: item, key: key(item)}
+	}
+	slices.SortFunc(entries, func(a, b keyed) int { return compare(a.key, b.key) })
+
+	sorted := make([]T, len(entries))
+	keys := make([]*repositories.Cursor, len(entries))
+	for i, entry := range entries {
+		sorted[i], keys[i] = entry.item, entry.key
+	}
+
+	result, hasMore := keyedPage(sorted, keys, compare, page)
+	return slices.Clone(result), hasMore
+}
+
+// compareCommentKeys orders the sort keys of comments for a sort order,
+// ranked orders best first, with the same tie-breakers as the Postgres
+// repository.
+func compareCommentKeys(sortOrder string) func(a, b *repositories.Cursor) int {
+	return func(a, b *repositories.Cursor) int {
+		switch sortOrder {
+		case constants.SortAsc:
+			return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+		case constants.SortDesc:
+			return -cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+		case constants.SortTop:
+			return -cmp.Or(cmp.Compare(a.Score, b.Score), a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+		case constants.SortHot:
+			return -cmp.Or(cmp.Compare(a.Hot, b.Hot), cmp.Compare(a.ID, b.ID))
+		default:
+			return -cmp.Or(a.LastActivityAt.Compare(b.LastActivityAt), cmp.Compare(a.ID, b.ID))
+		}
+	}
+}
+
+// comparePinned orders pinned comments, first pinned first.
+func comparePinned(a, b *repositories.Cursor) int {
+	return cmp.Or(a.PinnedAt.Compare(b.PinnedAt), cmp.Compare(a.ID, b.ID))
+}
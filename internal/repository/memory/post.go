@@ -1,9 +1,13 @@
 package memory
 
 import (
+	"cmp"
 	"posts_comments_service/internal/domain/constants"
 	"posts_comments_service/internal/domain/models"
 	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/votes"
+	"slices"
+	"strings"
 	"sync"
 )
 
@@ -12,13 +16,23 @@ type postRepository struct {
 	posts       []*models.Post
 	postsById   map[string]*models.Post
 	postIndices map[string]int
+	votes       voteBook
 }
 
-func NewPostRepository() repositories.PostRepository {
+// PostRepository is a post repository that also keeps the comment counters
+// of the posts, which the memory comment repository adjusts as comments are
+// created.
+type PostRepository interface {
+	repositories.PostRepository
+	AddComments(postID string, delta int)
+}
+
+func NewPostRepository() PostRepository {
 	return &postRepository{
 		posts:       make([]*models.Post, 0),
 		postsById:   make(map[string]*models.Post),
 		postIndices: make(map[string]int),
+		votes:       make(voteBook),
 	}
 }
 
@@ -43,50 +57,259 @@ func (r *postRepository) GetByID(id string) (*models.Post, error) {
 	return post, nil
 }
 
-func (r *postRepository) List(limit int, after *string, sortOrder string) ([]*models.Post, error) {
+func (r *postRepository) GetByIDs(ids []string) (map[string]*models.Post, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	total := len(r.posts)
-	if total == 0 {
-		return []*models.Post{}, nil
+	result := make(map[string]*models.Post, len(ids))
+	for _, id := range ids {
+		if post, ok := r.postsById[id]; ok {
+			result[id] = post
+		}
 	}
+	return result, nil
+}
 
-	if sortOrder == constants.SortDesc || sortOrder == "" {
-		startIdx := total - 1
-		if after != nil {
-			post, exists := r.postsById[*after]
-			if !exists {
-				return nil, repositories.ErrInvalidCursor
-			}
-			startIdx = r.postIndices[post.ID] - 1
-		}
+func (r *postRepository) Update(post *models.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.postIndices[post.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+
+	updated := *r.posts[idx]
+	updated.Title = post.Title
+	updated.Content = post.Content
+
+	r.posts[idx] = &updated
+	r.postsById[post.ID] = &updated
+	return nil
+}
+
+func (r *postRepository) SetCommentsEnabled(id string, enabled bool, closedBy *string, closedAt *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.postIndices[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+
+	updated := *r.posts[idx]
+	updated.AllowComments = enabled
+	updated.CommentsClosedBy = closedBy
+	updated.CommentsClosedAt = closedAt
+
+	r.posts[idx] = &updated
+	r.postsById[id] = &updated
+	return nil
+}
+
+func (r *postRepository) SetMaxCommentDepth(id string, maxDepth *int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.postIndices[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+
+	updated := *r.posts[idx]
+	updated.MaxCommentDepth = maxDepth
+
+	r.posts[idx] = &updated
+	r.postsById[id] = &updated
+	return nil
+}
+
+func (r *postRepository) Archive(createdBefore, archivedAt string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-		available := startIdx + 1
-		if available > limit {
-			available = limit
+	archived := 0
+	for idx, post := range r.posts {
+		if post.ArchivedAt != nil || !laterThan(createdBefore, post.CreatedAt) {
+			continue
 		}
 
-		result := make([]*models.Post, 0, available)
-		for i := 0; i < available; i++ {
-			result = append(result, r.posts[startIdx-i])
+		updated := *post
+		updated.ArchivedAt = &archivedAt
+
+		r.posts[idx] = &updated
+		r.postsById[post.ID] = &updated
+		archived++
+	}
+	return archived, nil
+}
+
+func (r *postRepository) SetTags(id string, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.postIndices[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+
+	updated := *r.posts[idx]
+	updated.Tags = slices.Clone(tags)
+
+	r.posts[idx] = &updated
+	r.postsById[id] = &updated
+	return nil
+}
+
+func (r *postRepository) SetCategory(id string, category *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.postIndices[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+
+	updated := *r.posts[idx]
+	updated.Category = category
+
+	r.posts[idx] = &updated
+	r.postsById[id] = &updated
+	return nil
+}
+
+func (r *postRepository) ListTags(prefix string, limit int) ([]*models.TagCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, post := range r.posts {
+		for _, tag := range post.Tags {
+			if strings.HasPrefix(tag, prefix) {
+				counts[tag]++
+			}
 		}
-		return result, nil
 	}
 
-	start := 0
-	if after != nil {
-		post, exists := r.postsById[*after]
-		if !exists {
-			return nil, repositories.ErrInvalidCursor
+	result := make([]*models.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, &models.TagCount{Tag: tag, Count: count})
+	}
+	slices.SortFunc(result, func(a, b *models.TagCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Tag, b.Tag))
+	})
+	return result[:min(limit, len(result))], nil
+}
+
+// AddComments adjusts the comment counter of a post.
+func (r *postRepository) AddComments(id string, delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.postIndices[id]
+	if !ok {
+		return
+	}
+
+	updated := *r.posts[idx]
+	updated.CommentsTotal += delta
+
+	r.posts[idx] = &updated
+	r.postsById[id] = &updated
+}
+
+func (r *postRepository) Vote(id string, voter string, value int) (*models.VoteTally, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.postIndices[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+
+	up, down := r.votes.cast(id, voter, value)
+
+	updated := *r.posts[idx]
+	updated.Upvotes += up
+	updated.Downvotes += down
+	updated.Score = updated.Upvotes - updated.Downvotes
+
+	r.posts[idx] = &updated
+	r.postsById[id] = &updated
+	return votes.Tally(id, updated.Upvotes, updated.Downvotes, value), nil
+}
+
+func (r *postRepository) GetVotes(voter string, ids []string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.votes.get(voter, ids), nil
+}
+
+func (r *postRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.postIndices[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+
+	r.posts = append(r.posts[:idx], r.posts[idx+1:]...)
+	delete(r.postsById, id)
+	delete(r.postIndices, id)
+	delete(r.votes, id)
+
+	for i := idx; i < len(r.posts); i++ {
+		r.postIndices[r.posts[i].ID] = i
+	}
+	return nil
+}
+
+func (r *postRepository) List(filter repositories.PostFilter, page repositories.Page) ([]*models.Post, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	posts := r.posts
+	if filter != (repositories.PostFilter{}) {
+		posts = nil
+		for _, post := range r.posts {
+			if matches(filter, post) {
+				posts = append(posts, post)
+			}
 		}
-		start = r.postIndices[post.ID] + 1
 	}
 
-	end := start + limit
-	if end > total {
-		end = total
+	// Paging by key keeps working after the cursor post is deleted or no
+	// longer matches the filter.
+	order := constants.SortDesc
+	if page.SortOrder == constants.SortAsc {
+		order = constants.SortAsc
 	}
+	result, hasMore := sortedPage(posts, repositories.PostCursor, compareCommentKeys(order), page)
+	return result, hasMore, nil
+}
+
+func (r *postRepository) Count(filter repositories.PostFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	return r.posts[start:end], nil
+	if filter == (repositories.PostFilter{}) {
+		return len(r.posts), nil
+	}
+
+	count := 0
+	for _, post := range r.posts {
+		if matches(filter, post) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func matches(filter repositories.PostFilter, post *models.Post) bool {
+	if filter.Category != "" && (post.Category == nil || *post.Category != filter.Category) {
+		return false
+	}
+	return filter.Tag == "" || slices.Contains(post.Tags, filter.Tag)
 }
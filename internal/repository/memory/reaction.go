@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+type reactionRepository struct {
+	mu sync.RWMutex
+	// reactions maps comment id -> emoji -> the users who reacted with it.
+	reactions map[string]map[string]map[string]struct{}
+}
+
+// ReactionRepository is a reaction repository that drops the reactions on
+// comments deleted from the memory store.
+type ReactionRepository interface {
+	repositories.ReactionRepository
+	CommentDependent
+}
+
+func NewReactionRepository() ReactionRepository {
+	return &reactionRepository{
+		reactions: make(map[string]map[string]map[string]struct{}),
+	}
+}
+
+func (r *reactionRepository) Add(commentID, emoji, user string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byEmoji, exists := r.reactions[commentID]
+	if !exists {
+		byEmoji = make(map[string]map[string]struct{})
+		r.reactions[commentID] = byEmoji
+	}
+
+	users, exists := byEmoji[emoji]
+	if !exists {
+		users = make(map[string]struct{})
+		byEmoji[emoji] = users
+	}
+	users[user] = struct{}{}
+	return nil
+}
+
+func (r *reactionRepository) Remove(commentID, emoji, user string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byEmoji := r.reactions[commentID]
+	users, exists := byEmoji[emoji]
+	if !exists {
+		return nil
+	}
+
+	delete(users, user)
+	if len(users) == 0 {
+		delete(byEmoji, emoji)
+	}
+	if len(byEmoji) == 0 {
+		delete(r.reactions, commentID)
+	}
+	return nil
+}
+
+func (r *reactionRepository) GetSummaries(commentIDs []string, user string) (map[string][]*models.ReactionSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string][]*models.ReactionSummary)
+	for _, id := range commentIDs {
+		byEmoji, exists := r.reactions[id]
+		if !exists {
+			continue
+		}
+
+		summaries := make([]*models.ReactionSummary, 0, len(byEmoji))
+		for emoji, users := range byEmoji {
+			_, reacted := users[user]
+			summaries = append(summaries, &models.ReactionSummary{
+				Emoji:       emoji,
+				Count:       len(users),
+				ReactedByMe: reacted,
+			})
+		}
+		slices.SortFunc(summaries, compareSummaries)
+		result[id] = summaries
+	}
+	return result, nil
+}
+
+// compareSummaries puts the most used emoji first, ties ordered by emoji,
+// the order the PostgreSQL repository returns.
+func compareSummaries(a, b *models.ReactionSummary) int {
+	if a.Count != b.Count {
+		return b.Count - a.Count
+	}
+	return strings.Compare(a.Emoji, b.Emoji)
+}
+
+func (r *reactionRepository) CommentsDeleted(_ string, commentIDs []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range commentIDs {
+		delete(r.reactions, id)
+	}
+}
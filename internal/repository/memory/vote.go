@@ -0,0 +1,36 @@
+package memory
+
+import "posts_comments_service/internal/domain/votes"
+
+// voteBook holds the votes of one repository: target id -> voter -> value.
+// It is guarded by the mutex of the repository that owns it.
+type voteBook map[string]map[string]int
+
+// cast replaces the vote of the voter on the target and returns the
+// resulting change of the upvote and downvote counters.
+func (b voteBook) cast(targetID, voter string, value int) (int, int) {
+	byVoter := b[targetID]
+	previous := byVoter[voter]
+
+	if value == 0 {
+		delete(byVoter, voter)
+	} else {
+		if byVoter == nil {
+			byVoter = make(map[string]int)
+			b[targetID] = byVoter
+		}
+		byVoter[voter] = value
+	}
+
+	return votes.Deltas(previous, value)
+}
+
+func (b voteBook) get(voter string, ids []string) map[string]int {
+	result := make(map[string]int)
+	for _, id := range ids {
+		if value, ok := b[id][voter]; ok {
+			result[id] = value
+		}
+	}
+	return result
+}
@@ -3,17 +3,69 @@ package postgres
 import (
 	"database/sql"
 	"posts_comments_service/internal/domain/constants"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/ranking"
 	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/votes"
 )
 
+// pathSeparator joins the ids in the materialized path of a comment: the ids
+// of its ancestors from the top-level comment down, followed by its own.
+const pathSeparator = "."
+
+const commentColumns = `id, post_id, parent_id, author, text, created_at, edited_at, deleted, replies_count, depth, descendants_count, score, hot, last_activity_at, upvotes, downvotes, pinned_at, locked, mentions`
+
+// pinnedFirst leads an ORDER BY list that puts the pinned comments of a
+// level first, ordered like pinnedKeyset, ahead of the rest.
+const pinnedFirst = `pinned_at ASC NULLS LAST, CASE WHEN pinned_at IS NOT NULL THEN id END, `
+
 type commentRepository struct {
 	db *sql.DB
 }
 
+func scanComment(row rowScanner) (*models.Comment, error) {
+	var comment models.Comment
+	var dbUUID uuid.UUID
+	var postUUID uuid.UUID
+	var parentUUID uuid.NullUUID
+	var createdAt time.Time
+	var editedAt sql.NullTime
+	var lastActivityAt time.Time
+	var pinnedAt sql.NullTime
+
+	if err := row.Scan(&dbUUID, &postUUID, &parentUUID, &comment.Author, &comment.Text, &createdAt, &editedAt, &comment.Deleted,
+		&comment.RepliesCount, &comment.Depth, &comment.DescendantsCount, &comment.Score, &comment.Hot, &lastActivityAt,
+		&comment.Upvotes, &comment.Downvotes, &pinnedAt, &comment.Locked, pq.Array(&comment.Mentions)); err != nil {
+		return nil, err
+	}
+
+	comment.ID = dbUUID.String()
+	comment.PostID = postUUID.String()
+	comment.CreatedAt = formatTime(createdAt)
+	comment.LastActivityAt = formatTime(lastActivityAt)
+
+	if parentUUID.Valid {
+		parentStr := parentUUID.UUID.String()
+		comment.ParentID = &parentStr
+	}
+	if editedAt.Valid {
+		formatted := formatTime(editedAt.Time)
+		comment.EditedAt = &formatted
+	}
+	if pinnedAt.Valid {
+		formatted := formatTime(pinnedAt.Time)
+		comment.PinnedAt = &formatted
+	}
+
+	return &comment, nil
+}
+
 func NewCommentRepository(db *sql.DB) repositories.CommentRepository {
 	return &commentRepository{db: db}
 }
@@ -28,73 +80,176 @@ func (r *commentRepository) Create(comment *models.Comment) error {
 		return repositories.ErrNotFound
 	}
 
-	var allowComments bool
-	err = r.db.QueryRow(`SELECT allow_comments FROM posts WHERE id = $1`, postUUID).Scan(&allowComments)
+	var parentUUID *uuid.UUID
+	if comment.ParentID != nil {
+		if id, err := uuid.Parse(*comment.ParentID); err == nil {
+			parentUUID = &id
+		} else {
+			return repositories.ErrNotFound
+		}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the post keeps it from being archived or allow_comments from
+	// being switched off until the insert commits, and serializes the counter
+	// updates of one thread.
+	var allowComments, archived bool
+	err = tx.QueryRow(`SELECT allow_comments, archived_at IS NOT NULL FROM posts WHERE id = $1 FOR UPDATE`, postUUID).
+		Scan(&allowComments, &archived)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return repositories.ErrNotFound
 		}
 		return err
 	}
+	if archived {
+		return repositories.ErrPostArchived
+	}
 	if !allowComments {
 		return repositories.ErrCommentsDisabled
 	}
 
-	var parentUUID *uuid.UUID
-	if comment.ParentID != nil {
-		if id, err := uuid.Parse(*comment.ParentID); err == nil {
-			parentUUID = &id
-		} else {
-			return repositories.ErrNotFound
+	depth := 1
+	path := comment.ID
+	if parentUUID != nil {
+		var parentPostUUID uuid.UUID
+		var parentDeleted bool
+		var parentDepth int
+		var parentPath string
+		// The parent row is locked for update: its counters change below.
+		err = tx.QueryRow(`SELECT post_id, deleted, depth, path FROM comments WHERE id = $1 FOR UPDATE`, parentUUID).
+			Scan(&parentPostUUID, &parentDeleted, &parentDepth, &parentPath)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return repositories.ErrParentNotFound
+			}
+			return err
+		}
+		if parentPostUUID != postUUID {
+			return repositories.ErrParentNotFound
 		}
+		if parentDeleted {
+			return repositories.ErrCommentDeleted
+		}
+
+		// The parent and its ancestors are locked until the insert commits,
+		// so none of them can lock the thread in between.
+		var threadLocked bool
+		err = tx.QueryRow(`
+            SELECT COALESCE(bool_or(locked), false) FROM (
+                SELECT locked FROM comments
+                WHERE id = ANY(string_to_array($1, '`+pathSeparator+`')::uuid[])
+                FOR UPDATE
+            ) AS thread`, parentPath).Scan(&threadLocked)
+		if err != nil {
+			return err
+		}
+		if threadLocked {
+			return repositories.ErrThreadLocked
+		}
+		depth = parentDepth + 1
+		path = parentPath + pathSeparator + comment.ID
 	}
 
-	_, err = r.db.Exec(`
-        INSERT INTO comments (id, post_id, parent_id, author, text, created_at)
-        VALUES ($1, $2, $3, $4, $5, $6)`,
-		comment.ID, postUUID, parentUUID, comment.Author, comment.Text, comment.CreatedAt)
+	_, err = tx.Exec(`
+        INSERT INTO comments (id, post_id, parent_id, author, text, created_at, depth, path, hot, last_activity_at, mentions)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $6, $10)`,
+		comment.ID, postUUID, parentUUID, comment.Author, comment.Text, comment.CreatedAt, depth, path, comment.Hot,
+		pq.Array(comment.Mentions))
 	if err != nil {
 		return err
 	}
+	comment.Depth = depth
 
-	return nil
+	if err := saveMentions(tx, comment.ID, comment.Mentions); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`UPDATE posts SET comments_total = comments_total + 1 WHERE id = $1`, postUUID); err != nil {
+		return err
+	}
+
+	// Every id on the path above the new comment gains a descendant and
+	// fresh activity; the direct parent also gains a reply.
+	if parentUUID != nil {
+		ancestors := strings.Split(path, pathSeparator)
+		_, err = tx.Exec(`
+            UPDATE comments
+            SET descendants_count = descendants_count + 1,
+                replies_count = replies_count + CASE WHEN id = $2 THEN 1 ELSE 0 END,
+                last_activity_at = GREATEST(last_activity_at, $3::timestamptz)
+            WHERE id = ANY($1::uuid[])`,
+			pq.Array(ancestors[:len(ancestors)-1]), parentUUID, comment.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (r *commentRepository) GetByPostID(postID string, parentID *string, limit int, after *string, sortOrder string) ([]*models.Comment, bool, error) {
-	var query string
-	var afterTime *time.Time
+func (r *commentRepository) GetByID(id string) (*models.Comment, error) {
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
 
-	if after != nil {
-		id, err := uuid.Parse(*after)
-		if err == nil {
-			row := r.db.QueryRow("SELECT created_at FROM comments WHERE id = $1", id)
-			var t time.Time
-			if err := row.Scan(&t); err == nil {
-				afterTime = &t
-			} else if err == sql.ErrNoRows {
-				return nil, false, repositories.ErrInvalidCursor
-			}
+	row := r.db.QueryRow(`SELECT `+commentColumns+` FROM comments WHERE id = $1`, commentUUID)
+	comment, err := scanComment(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrNotFound
 		}
+		return nil, err
 	}
+	return comment, nil
+}
 
-	if sortOrder == constants.SortAsc {
-		query = `
-            SELECT id, post_id, parent_id, author, text, created_at
-            FROM comments
-            WHERE post_id = $1 AND (parent_id IS NULL AND $2::uuid IS NULL OR parent_id = $2)
-            AND ($3::timestamptz IS NULL OR created_at > $3)
-            ORDER BY created_at ASC
-            LIMIT $4`
-	} else {
-		query = `
-            SELECT id, post_id, parent_id, author, text, created_at
-            FROM comments
-            WHERE post_id = $1 AND (parent_id IS NULL AND $2::uuid IS NULL OR parent_id = $2)
-            AND ($3::timestamptz IS NULL OR created_at < $3)
-            ORDER BY created_at DESC
-            LIMIT $4`
+// GetAncestors reads the ancestor ids from the materialized path of the comment.
+func (r *commentRepository) GetAncestors(id string) ([]*models.Comment, error) {
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repositories.ErrNotFound
 	}
 
+	rows, err := r.db.Query(`
+        SELECT `+commentColumns+`
+        FROM comments
+        WHERE id = ANY(string_to_array((SELECT path FROM comments WHERE id = $1), '`+pathSeparator+`')::uuid[])
+            AND id <> $1
+        ORDER BY depth`, commentUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ancestors := make([]*models.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A top-level comment has no ancestors; tell it apart from a missing one.
+	if len(ancestors) == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return nil, err
+		}
+	}
+	return ancestors, nil
+}
+
+func (r *commentRepository) GetByPostID(postID string, parentID *string, page repositories.Page) ([]*models.Comment, bool, error) {
 	postUUID, err := uuid.Parse(postID)
 	if err != nil {
 		return nil, false, repositories.ErrNotFound
@@ -109,7 +264,28 @@ func (r *commentRepository) GetByPostID(postID string, parentID *string, limit i
 		}
 	}
 
-	rows, err := r.db.Query(query, postUUID, parentUUID, afterTime, limit+1)
+	if parentUUID != nil {
+		key, desc := commentKeyset(page.SortOrder)
+		query := `SELECT ` + commentColumns + ` FROM comments WHERE post_id = $1 AND parent_id = $2`
+		return r.levelPage(query, []any{postUUID, parentUUID}, page, key, desc)
+	}
+
+	// Only top-level comments are pinned; they are paged apart from the rest.
+	return repositories.PinnedFirst(page, func(pinned bool, segment repositories.Page) ([]*models.Comment, bool, error) {
+		query := `SELECT ` + commentColumns + ` FROM comments WHERE post_id = $1 AND parent_id IS NULL`
+		if pinned {
+			return r.levelPage(query+` AND pinned_at IS NOT NULL`, []any{postUUID}, segment, pinnedKeyset, false)
+		}
+		key, desc := commentKeyset(segment.SortOrder)
+		return r.levelPage(query+` AND pinned_at IS NULL`, []any{postUUID}, segment, key, desc)
+	})
+}
+
+// levelPage runs query, which selects the comments of one level, for a page
+// over the keyset.
+func (r *commentRepository) levelPage(query string, args []any, page repositories.Page, key keyset, desc bool) ([]*models.Comment, bool, error) {
+	clause, args := keysetClause(page, key, desc, args)
+	rows, err := r.db.Query(query+clause, args...)
 	if err != nil {
 		return nil, false, err
 	}
@@ -117,94 +293,564 @@ func (r *commentRepository) GetByPostID(postID string, parentID *string, limit i
 
 	var comments []*models.Comment
 	for rows.Next() {
-		var comment models.Comment
-		var dbUUID uuid.UUID
-		var postUUID uuid.UUID
-		var parentUUID uuid.NullUUID
-		var createdAt time.Time
-
-		if err := rows.Scan(&dbUUID, &postUUID, &parentUUID, &comment.Author, &comment.Text, &createdAt); err != nil {
+		comment, err := scanComment(rows)
+		if err != nil {
 			return nil, false, err
 		}
-
-		comment.ID = dbUUID.String()
-		comment.PostID = postUUID.String()
-		comment.CreatedAt = createdAt.Format(time.RFC3339)
-
-		if parentUUID.Valid {
-			parentStr := parentUUID.UUID.String()
-			comment.ParentID = &parentStr
-		}
-
-		comments = append(comments, &comment)
+		comments = append(comments, comment)
 	}
 
 	hasMore := false
-	if len(comments) > limit {
+	if len(comments) > page.Limit {
 		hasMore = true
-		comments = comments[:limit]
+		comments = comments[:page.Limit]
+	}
+	if page.Backward {
+		slices.Reverse(comments)
 	}
 
 	return comments, hasMore, nil
 }
 
-func (r *commentRepository) Count(postID string, parentID *string) (int, error) {
+func (r *commentRepository) GetRepliesPages(parentIDs []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error) {
+	return r.firstPages("parent_id", "parent_id = ANY($1::uuid[])", parentIDs, limit, sortOrder)
+}
+
+func (r *commentRepository) GetTopLevelPages(postIDs []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error) {
+	return r.firstPages("post_id", "post_id = ANY($1::uuid[]) AND parent_id IS NULL", postIDs, limit, sortOrder)
+}
+
+// firstPages fetches the first page of several levels in a single query,
+// numbering the rows of every level and counting them with window functions.
+func (r *commentRepository) firstPages(levelColumn, filter string, ids []string, limit int, sortOrder string) (map[string]*repositories.CommentPage, error) {
+	result := make(map[string]*repositories.CommentPage, len(ids))
+	for _, id := range ids {
+		result[id] = &repositories.CommentPage{Comments: []*models.Comment{}}
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	// An empty page still reports the total, which is read from the first
+	// row of each level.
+	limit = max(limit, 0)
+
+	key, desc := commentKeyset(sortOrder)
+	direction := constants.SortAsc
+	if desc {
+		direction = constants.SortDesc
+	}
+
 	query := `
-        SELECT COUNT(*)
-        FROM comments
-        WHERE post_id = $1 AND (parent_id IS NULL AND $2::uuid IS NULL OR parent_id = $2)`
+        SELECT ` + commentColumns + `, level_total
+        FROM (
+            SELECT *,
+                ROW_NUMBER() OVER (PARTITION BY ` + levelColumn + ` ORDER BY ` + pinnedFirst + key.orderBy(direction) + `) AS level_position,
+                COUNT(*) OVER (PARTITION BY ` + levelColumn + `) AS level_total
+            FROM comments
+            WHERE ` + filter + `
+        ) ranked
+        WHERE level_position <= $2
+        ORDER BY ` + levelColumn + `, level_position`
+
+	rows, err := r.db.Query(query, pq.Array(ids), limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	for rows.Next() {
+		var total int
+		comment, err := scanComment(withExtra(rows, &total))
+		if err != nil {
+			return nil, err
+		}
+
+		levelKey := comment.PostID
+		if levelColumn == "parent_id" {
+			levelKey = *comment.ParentID
+		}
+
+		page, ok := result[levelKey]
+		if !ok {
+			continue
+		}
+		page.TotalCount = total
+		if len(page.Comments) == limit {
+			page.HasMore = limit > 0
+			continue
+		}
+		page.Comments = append(page.Comments, comment)
+	}
+
+	return result, rows.Err()
+}
+
+// GetTree walks the thread with a recursive CTE. The LATERAL subquery keeps
+// only the first maxChildren replies of every node, and position lets the
+// query fetch one extra root to detect more roots without expanding it.
+func (r *commentRepository) GetTree(postID string, rootID *string, maxDepth, maxChildren int) ([]*models.CommentNode, bool, error) {
 	postUUID, err := uuid.Parse(postID)
 	if err != nil {
-		return 0, repositories.ErrNotFound
+		return nil, false, repositories.ErrNotFound
 	}
 
-	var parentUUID *uuid.UUID
-	if parentID != nil {
-		if id, err := uuid.Parse(*parentID); err == nil {
-			parentUUID = &id
-		} else {
-			return 0, repositories.ErrNotFound
+	args := []any{postUUID, maxDepth, maxChildren}
+	rootFilter := `post_id = $1 AND parent_id IS NULL`
+	if rootID != nil {
+		rootUUID, err := uuid.Parse(*rootID)
+		if err != nil {
+			return nil, false, repositories.ErrNotFound
 		}
+		args = append(args, rootUUID)
+		rootFilter = `post_id = $1 AND id = $4`
 	}
 
-	var count int
-	err = r.db.QueryRow(query, postUUID, parentUUID).Scan(&count)
+	query := `
+        WITH RECURSIVE tree AS (
+            (SELECT ` + commentColumns + `,
+                ROW_NUMBER() OVER (ORDER BY ` + pinnedFirst + `created_at, id) AS position,
+                1 AS level
+            FROM comments
+            WHERE ` + rootFilter + `
+            ORDER BY ` + pinnedFirst + `created_at, id
+            LIMIT $3 + 1)
+          UNION ALL
+            SELECT child.*, tree.level + 1
+            FROM tree
+            CROSS JOIN LATERAL (
+                SELECT ` + commentColumns + `,
+                    ROW_NUMBER() OVER (ORDER BY created_at, id) AS position
+                FROM comments
+                WHERE parent_id = tree.id
+                ORDER BY created_at, id
+                LIMIT $3
+            ) child
+            WHERE tree.level < $2 AND tree.position <= $3
+        )
+        SELECT ` + commentColumns + `, position, level
+        FROM tree
+        ORDER BY level, position`
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return 0, err
+		return nil, false, err
 	}
+	defer rows.Close()
 
-	return count, nil
+	var roots []*models.CommentNode
+	hasMore := false
+	nodes := make(map[string]*models.CommentNode)
+	for rows.Next() {
+		var position, level int
+		comment, err := scanComment(withExtra(rows, &position, &level))
+		if err != nil {
+			return nil, false, err
+		}
+
+		node := &models.CommentNode{Comment: comment, Children: []*models.CommentNode{}}
+		if level == 1 {
+			if position > maxChildren {
+				hasMore = true
+				continue
+			}
+			roots = append(roots, node)
+		} else {
+			parent := nodes[*comment.ParentID]
+			parent.Children = append(parent.Children, node)
+		}
+		nodes[comment.ID] = node
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if rootID != nil && len(roots) == 0 {
+		return nil, false, repositories.ErrNotFound
+	}
+	for _, node := range nodes {
+		node.HasMoreChildren = node.Comment.RepliesCount > len(node.Children)
+	}
+
+	return roots, hasMore, nil
 }
 
-func (r *commentRepository) CountReplies(postID string) (map[string]int, error) {
-	query := `
-		SELECT parent_id, COUNT(*)
-		FROM comments
-		WHERE post_id = $1 AND parent_id IS NOT NULL
-		GROUP BY parent_id
-	`
+// Edit replaces the comment text and keeps the previous version in comment_revisions.
+func (r *commentRepository) Edit(id string, text string, mentions []string, editedAt string) (*models.Comment, error) {
+	if len(text) > constants.MaxCommentLength {
+		return nil, repositories.ErrTextTooLong
+	}
 
-	postUUID, err := uuid.Parse(postID)
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var previousText string
+	var previousAt time.Time
+	var deleted bool
+	err = tx.QueryRow(`
+        SELECT text, COALESCE(edited_at, created_at), deleted
+        FROM comments WHERE id = $1
+        FOR UPDATE`, commentUUID).Scan(&previousText, &previousAt, &deleted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	if deleted {
+		return nil, repositories.ErrCommentDeleted
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO comment_revisions (comment_id, text, created_at)
+        VALUES ($1, $2, $3)`,
+		commentUUID, previousText, previousAt)
+	if err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(`
+        UPDATE comments SET text = $2, edited_at = $3, mentions = $4
+        WHERE id = $1
+        RETURNING `+commentColumns,
+		commentUUID, text, editedAt, pq.Array(mentions))
+	comment, err := scanComment(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM comment_mentions WHERE comment_id = $1`, commentUUID); err != nil {
+		return nil, err
+	}
+	if err := saveMentions(tx, id, mentions); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (r *commentRepository) GetRevisions(commentID string) ([]*models.CommentRevision, error) {
+	commentUUID, err := uuid.Parse(commentID)
 	if err != nil {
 		return nil, repositories.ErrNotFound
 	}
 
-	rows, err := r.db.Query(query, postUUID)
+	rows, err := r.db.Query(`
+        SELECT text, created_at
+        FROM comment_revisions
+        WHERE comment_id = $1
+        ORDER BY created_at ASC, id ASC`, commentUUID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	result := make(map[string]int)
+	revisions := make([]*models.CommentRevision, 0)
 	for rows.Next() {
-		var parentID uuid.UUID
-		var count int
-		if err := rows.Scan(&parentID, &count); err != nil {
+		var revision models.CommentRevision
+		var createdAt time.Time
+		if err := rows.Scan(&revision.Text, &createdAt); err != nil {
 			return nil, err
 		}
-		result[parentID.String()] = count
+		revision.CommentID = commentID
+		revision.CreatedAt = formatTime(createdAt)
+		revisions = append(revisions, &revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// Delete tombstones the comment instead of removing the row, so that the
+// ON DELETE CASCADE on parent_id does not take the replies with it.
+func (r *commentRepository) Delete(id string) error {
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        UPDATE comments SET text = '', author = '', edited_at = NULL, deleted = TRUE, pinned_at = NULL, mentions = '{}'
+        WHERE id = $1`, commentUUID)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM comment_revisions WHERE comment_id = $1`, commentUUID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM comment_mentions WHERE comment_id = $1`, commentUUID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Pin locks the post, which serializes the pins of its comments, and
+// checks the limit before pinning.
+func (r *commentRepository) Pin(id string, pinnedAt string, maxPinned int) (*models.Comment, error) {
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var postUUID uuid.UUID
+	err = tx.QueryRow(`
+        SELECT p.id FROM posts p
+        JOIN comments c ON c.post_id = p.id
+        WHERE c.id = $1
+        FOR UPDATE OF p`, commentUUID).Scan(&postUUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+
+	comment, err := scanComment(tx.QueryRow(`SELECT `+commentColumns+` FROM comments WHERE id = $1`, commentUUID))
+	if err != nil {
+		return nil, err
+	}
+	if comment.PinnedAt != nil {
+		return comment, tx.Commit()
+	}
+
+	var pinned int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM comments WHERE post_id = $1 AND pinned_at IS NOT NULL`, postUUID).Scan(&pinned)
+	if err != nil {
+		return nil, err
+	}
+	if pinned >= maxPinned {
+		return nil, repositories.ErrPinLimitReached
+	}
+
+	comment, err = scanComment(tx.QueryRow(`
+        UPDATE comments SET pinned_at = $2
+        WHERE id = $1
+        RETURNING `+commentColumns,
+		commentUUID, pinnedAt))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (r *commentRepository) Unpin(id string) (*models.Comment, error) {
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
+
+	comment, err := scanComment(r.db.QueryRow(`
+        UPDATE comments SET pinned_at = NULL
+        WHERE id = $1
+        RETURNING `+commentColumns, commentUUID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (r *commentRepository) SetLocked(id string, locked bool) (*models.Comment, error) {
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
+
+	comment, err := scanComment(r.db.QueryRow(`
+        UPDATE comments SET locked = $2
+        WHERE id = $1
+        RETURNING `+commentColumns, commentUUID, locked))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// saveMentions indexes the users mentioned by a comment.
+func saveMentions(tx *sql.Tx, commentID string, mentions []string) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+        INSERT INTO comment_mentions (comment_id, user_name)
+        SELECT $1, unnest($2::text[])
+        ON CONFLICT DO NOTHING`,
+		commentID, pq.Array(mentions))
+	return err
+}
+
+func (r *commentRepository) GetMentioning(user string, page repositories.Page) ([]*models.Comment, bool, error) {
+	query := `
+        SELECT ` + commentColumns + ` FROM comments
+        WHERE id IN (SELECT comment_id FROM comment_mentions WHERE user_name = $1)`
+	return r.levelPage(query, []any{user}, page, createdKeyset, true)
+}
+
+func (r *commentRepository) CountMentioning(user string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM comment_mentions WHERE user_name = $1`, user).Scan(&count)
+	return count, err
+}
+
+// Vote locks the comment row, records the vote and recomputes the counters,
+// score and hot rank from the locked values.
+func (r *commentRepository) Vote(id string, voter string, value int) (*models.VoteTally, error) {
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var upvotes, downvotes int
+	var createdAt time.Time
+	var deleted bool
+	err = tx.QueryRow(`
+        SELECT upvotes, downvotes, created_at, deleted
+        FROM comments WHERE id = $1
+        FOR UPDATE`, commentUUID).Scan(&upvotes, &downvotes, &createdAt, &deleted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	if deleted {
+		return nil, repositories.ErrCommentDeleted
+	}
+
+	up, down, err := castVote(tx, commentVotes, commentUUID, voter, value)
+	if err != nil {
+		return nil, err
+	}
+	upvotes += up
+	downvotes += down
+	score := upvotes - downvotes
+
+	_, err = tx.Exec(`
+        UPDATE comments SET upvotes = $2, downvotes = $3, score = $4, hot = $5
+        WHERE id = $1`,
+		commentUUID, upvotes, downvotes, score, ranking.Hot(score, createdAt))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return votes.Tally(id, upvotes, downvotes, value), nil
+}
+
+func (r *commentRepository) GetVotes(voter string, ids []string) (map[string]int, error) {
+	return getVotes(r.db, commentVotes, voter, ids)
+}
+
+// DeleteByPostID has nothing to do: the comments are removed together with
+// their post by ON DELETE CASCADE.
+func (r *commentRepository) DeleteByPostID(postID string) error {
+	return nil
+}
+
+// CountDescendants and CountAll read the counters maintained by Create.
+func (r *commentRepository) CountDescendants(id string) (int, error) {
+	commentUUID, err := uuid.Parse(id)
+	if err != nil {
+		return 0, repositories.ErrNotFound
+	}
+
+	var count int
+	err = r.db.QueryRow(`SELECT descendants_count FROM comments WHERE id = $1`, commentUUID).Scan(&count)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, repositories.ErrNotFound
+		}
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *commentRepository) CountAll(postID string) (int, error) {
+	postUUID, err := uuid.Parse(postID)
+	if err != nil {
+		return 0, repositories.ErrNotFound
 	}
 
-	return result, nil
+	var count int
+	err = r.db.QueryRow(`SELECT comments_total FROM posts WHERE id = $1`, postUUID).Scan(&count)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, repositories.ErrNotFound
+		}
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *commentRepository) Count(postID string, parentID *string) (int, error) {
+	query := `
+        SELECT COUNT(*)
+        FROM comments
+        WHERE post_id = $1 AND (parent_id IS NULL AND $2::uuid IS NULL OR parent_id = $2)`
+
+	postUUID, err := uuid.Parse(postID)
+	if err != nil {
+		return 0, repositories.ErrNotFound
+	}
+
+	var parentUUID *uuid.UUID
+	if parentID != nil {
+		if id, err := uuid.Parse(*parentID); err == nil {
+			parentUUID = &id
+		} else {
+			return 0, repositories.ErrNotFound
+		}
+	}
+
+	var count int
+	err = r.db.QueryRow(query, postUUID, parentUUID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
 }
@@ -0,0 +1,369 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"posts_comments_service/internal/domain/constants"
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/repository/postgres"
+)
+
+// openTestDB connects to the database from TEST_POSTGRES_DSN and migrates it.
+// Tests that need PostgreSQL are skipped when the variable is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	m, err := migrate.New("file://../../../migrations", dsn)
+	require.NoError(t, err)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(t, err)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func TestGetByPostID_SharedTimestamps(t *testing.T) {
+	db := openTestDB(t)
+	postRepo := postgres.NewPostRepository(db)
+	commentRepo := postgres.NewCommentRepository(db)
+
+	createdAt := "2025-07-11T05:00:21.123456Z"
+	post := &models.Post{
+		ID:            uuid.NewString(),
+		Title:         "Title",
+		Content:       "Content",
+		Author:        "Author",
+		AllowComments: true,
+		CreatedAt:     createdAt,
+	}
+	require.NoError(t, postRepo.Create(post))
+	t.Cleanup(func() { _ = postRepo.Delete(post.ID) })
+
+	const total = 25
+	for i := 0; i < total; i++ {
+		err := commentRepo.Create(&models.Comment{
+			ID:        uuid.NewString(),
+			PostID:    post.ID,
+			Author:    "Author",
+			Text:      fmt.Sprintf("Comment %d", i),
+			CreatedAt: createdAt,
+		})
+		require.NoError(t, err)
+	}
+
+	for _, sortOrder := range []string{constants.SortAsc, constants.SortDesc} {
+		for _, backward := range []bool{false, true} {
+			seen := make(map[string]bool)
+			page := repositories.Page{Limit: 4, SortOrder: sortOrder, Backward: backward}
+
+			for {
+				comments, hasMore, err := commentRepo.GetByPostID(post.ID, nil, page)
+				require.NoError(t, err)
+
+				for _, comment := range comments {
+					assert.False(t, seen[comment.ID], "comment %s returned twice", comment.ID)
+					seen[comment.ID] = true
+				}
+				if !hasMore {
+					break
+				}
+
+				if backward {
+					page.Before = repositories.CommentCursor(comments[0])
+				} else {
+					page.After = repositories.CommentCursor(comments[len(comments)-1])
+				}
+			}
+
+			assert.Len(t, seen, total, "sort order %s, backward %v", sortOrder, backward)
+		}
+	}
+}
+
+func TestGetTree_Limits(t *testing.T) {
+	db := openTestDB(t)
+	postRepo := postgres.NewPostRepository(db)
+	commentRepo := postgres.NewCommentRepository(db)
+
+	post := &models.Post{
+		ID:            uuid.NewString(),
+		Title:         "Title",
+		Content:       "Content",
+		Author:        "Author",
+		AllowComments: true,
+		CreatedAt:     "2025-07-11T05:00:21.123456Z",
+	}
+	require.NoError(t, postRepo.Create(post))
+	t.Cleanup(func() { _ = postRepo.Delete(post.ID) })
+
+	add := func(parentID *string, i int) *models.Comment {
+		comment := &models.Comment{
+			ID:        uuid.NewString(),
+			PostID:    post.ID,
+			ParentID:  parentID,
+			Author:    "Author",
+			Text:      "Comment",
+			CreatedAt: fmt.Sprintf("2025-07-11T05:01:%02d.000000Z", i),
+		}
+		require.NoError(t, commentRepo.Create(comment))
+		return comment
+	}
+
+	root := add(nil, 0)
+	add(nil, 1)
+	add(nil, 2)
+	child := add(&root.ID, 3)
+	add(&root.ID, 4)
+	add(&root.ID, 5)
+	grandchild := add(&child.ID, 6)
+	add(&grandchild.ID, 7)
+
+	roots, hasMore, err := commentRepo.GetTree(post.ID, nil, 3, 2)
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	require.Len(t, roots, 2)
+	assert.Equal(t, root.ID, roots[0].Comment.ID)
+	assert.True(t, roots[0].HasMoreChildren)
+	require.Len(t, roots[0].Children, 2)
+	assert.Equal(t, child.ID, roots[0].Children[0].Comment.ID)
+	require.Len(t, roots[0].Children[0].Children, 1)
+	assert.Empty(t, roots[0].Children[0].Children[0].Children)
+	assert.True(t, roots[0].Children[0].Children[0].HasMoreChildren)
+	assert.Empty(t, roots[1].Children)
+
+	subtree, hasMore, err := commentRepo.GetTree(post.ID, &child.ID, 10, 10)
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	require.Len(t, subtree, 1)
+	assert.Equal(t, child.ID, subtree[0].Comment.ID)
+	require.Len(t, subtree[0].Children, 1)
+	require.Len(t, subtree[0].Children[0].Children, 1)
+}
+
+func TestMaterializedPath_AncestorsAndCounts(t *testing.T) {
+	db := openTestDB(t)
+	postRepo := postgres.NewPostRepository(db)
+	commentRepo := postgres.NewCommentRepository(db)
+
+	post := &models.Post{
+		ID:            uuid.NewString(),
+		Title:         "Title",
+		Content:       "Content",
+		Author:        "Author",
+		AllowComments: true,
+		CreatedAt:     "2025-07-11T05:00:21.123456Z",
+	}
+	require.NoError(t, postRepo.Create(post))
+	t.Cleanup(func() { _ = postRepo.Delete(post.ID) })
+
+	var chain []*models.Comment
+	var parentID *string
+	for i := 0; i < 4; i++ {
+		comment := &models.Comment{
+			ID:        uuid.NewString(),
+			PostID:    post.ID,
+			ParentID:  parentID,
+			Author:    "Author",
+			Text:      "Comment",
+			CreatedAt: post.CreatedAt,
+		}
+		require.NoError(t, commentRepo.Create(comment))
+		assert.Equal(t, i+1, comment.Depth)
+		chain = append(chain, comment)
+		parentID = &comment.ID
+	}
+
+	ancestors, err := commentRepo.GetAncestors(chain[3].ID)
+	require.NoError(t, err)
+	require.Len(t, ancestors, 3)
+	for i, ancestor := range ancestors {
+		assert.Equal(t, chain[i].ID, ancestor.ID)
+	}
+
+	descendants, err := commentRepo.CountDescendants(chain[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, descendants)
+
+	total, err := commentRepo.CountAll(post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+
+	stored, err := postRepo.GetByID(post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, stored.CommentsTotal)
+
+	root, err := commentRepo.GetByID(chain[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, root.DescendantsCount)
+	assert.Equal(t, 1, root.RepliesCount)
+}
+
+func TestGetByPostID_PinnedFirst(t *testing.T) {
+	db := openTestDB(t)
+	postRepo := postgres.NewPostRepository(db)
+	commentRepo := postgres.NewCommentRepository(db)
+
+	post := &models.Post{
+		ID:            uuid.NewString(),
+		Title:         "Title",
+		Content:       "Content",
+		Author:        "Author",
+		AllowComments: true,
+		CreatedAt:     "2025-07-11T05:00:21.123456Z",
+	}
+	require.NoError(t, postRepo.Create(post))
+	t.Cleanup(func() { _ = postRepo.Delete(post.ID) })
+
+	var comments []*models.Comment
+	for i := 0; i < 5; i++ {
+		comment := &models.Comment{
+			ID:        uuid.NewString(),
+			PostID:    post.ID,
+			Author:    "Author",
+			Text:      fmt.Sprintf("Comment %d", i),
+			CreatedAt: fmt.Sprintf("2025-07-11T05:01:%02d.000000Z", i),
+		}
+		require.NoError(t, commentRepo.Create(comment))
+		comments = append(comments, comment)
+	}
+
+	_, err := commentRepo.Pin(comments[3].ID, "2025-07-12T00:00:00Z", 2)
+	require.NoError(t, err)
+	_, err = commentRepo.Pin(comments[1].ID, "2025-07-12T00:00:01Z", 2)
+	require.NoError(t, err)
+	_, err = commentRepo.Pin(comments[0].ID, "2025-07-12T00:00:02Z", 2)
+	assert.ErrorIs(t, err, repositories.ErrPinLimitReached)
+
+	want := []string{comments[3].ID, comments[1].ID, comments[4].ID, comments[2].ID, comments[0].ID}
+	for _, backward := range []bool{false, true} {
+		var walked []string
+		page := repositories.Page{Limit: 2, SortOrder: constants.SortDesc, Backward: backward}
+		for {
+			result, hasMore, err := commentRepo.GetByPostID(post.ID, nil, page)
+			require.NoError(t, err)
+			ids := make([]string, len(result))
+			for i, comment := range result {
+				ids[i] = comment.ID
+			}
+			if backward {
+				walked = append(ids, walked...)
+			} else {
+				walked = append(walked, ids...)
+			}
+			if !hasMore {
+				break
+			}
+			if backward {
+				page.Before = repositories.CommentCursor(result[0])
+			} else {
+				page.After = repositories.CommentCursor(result[len(result)-1])
+			}
+		}
+		assert.Equal(t, want, walked, "backward %v", backward)
+	}
+
+	pages, err := commentRepo.GetTopLevelPages([]string{post.ID}, 3, constants.SortDesc)
+	require.NoError(t, err)
+	require.Len(t, pages[post.ID].Comments, 3)
+	assert.Equal(t, want[:3], []string{pages[post.ID].Comments[0].ID, pages[post.ID].Comments[1].ID, pages[post.ID].Comments[2].ID})
+}
+
+func TestTopLevelPages_EmptyPageCounts(t *testing.T) {
+	db := openTestDB(t)
+	postRepo := postgres.NewPostRepository(db)
+	commentRepo := postgres.NewCommentRepository(db)
+
+	post := &models.Post{
+		ID:            uuid.NewString(),
+		Title:         "Title",
+		Content:       "Content",
+		Author:        "Author",
+		AllowComments: true,
+		CreatedAt:     "2025-07-11T05:00:21.123456Z",
+	}
+	require.NoError(t, postRepo.Create(post))
+	t.Cleanup(func() { _ = postRepo.Delete(post.ID) })
+
+	for i := 0; i < 3; i++ {
+		err := commentRepo.Create(&models.Comment{
+			ID:        uuid.NewString(),
+			PostID:    post.ID,
+			Author:    "Author",
+			Text:      fmt.Sprintf("Comment %d", i),
+			CreatedAt: post.CreatedAt,
+		})
+		require.NoError(t, err)
+	}
+
+	// first: 0 returns no comments but still reports how many there are.
+	pages, err := commentRepo.GetTopLevelPages([]string{post.ID}, 0, constants.SortAsc)
+	require.NoError(t, err)
+	require.Contains(t, pages, post.ID)
+	assert.Empty(t, pages[post.ID].Comments)
+	assert.False(t, pages[post.ID].HasMore)
+	assert.Equal(t, 3, pages[post.ID].TotalCount)
+}
+
+func TestCreate_LockedThreadAndArchivedPost(t *testing.T) {
+	db := openTestDB(t)
+	postRepo := postgres.NewPostRepository(db)
+	commentRepo := postgres.NewCommentRepository(db)
+
+	post := &models.Post{
+		ID:            uuid.NewString(),
+		Title:         "Title",
+		Content:       "Content",
+		Author:        "Author",
+		AllowComments: true,
+		CreatedAt:     "2025-07-11T05:00:21.123456Z",
+	}
+	require.NoError(t, postRepo.Create(post))
+	t.Cleanup(func() { _ = postRepo.Delete(post.ID) })
+
+	newComment := func(parentID *string) *models.Comment {
+		return &models.Comment{
+			ID:        uuid.NewString(),
+			PostID:    post.ID,
+			ParentID:  parentID,
+			Author:    "Author",
+			Text:      "Text",
+			CreatedAt: post.CreatedAt,
+		}
+	}
+
+	root := newComment(nil)
+	require.NoError(t, commentRepo.Create(root))
+	child := newComment(&root.ID)
+	require.NoError(t, commentRepo.Create(child))
+
+	_, err := commentRepo.SetLocked(root.ID, true)
+	require.NoError(t, err)
+
+	// A lock anywhere above the parent closes the branch.
+	err = commentRepo.Create(newComment(&child.ID))
+	assert.ErrorIs(t, err, repositories.ErrThreadLocked)
+	require.NoError(t, commentRepo.Create(newComment(nil)))
+
+	_, err = postRepo.Archive("2025-07-11T05:00:22Z", "2025-07-12T00:00:00Z")
+	require.NoError(t, err)
+
+	err = commentRepo.Create(newComment(nil))
+	assert.ErrorIs(t, err, repositories.ErrPostArchived)
+}
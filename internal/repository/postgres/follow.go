@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+type followRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRepository(db *sql.DB) repositories.FollowRepository {
+	return &followRepository{db: db}
+}
+
+func (r *followRepository) FollowPost(postID, user string) error {
+	postUUID, err := uuid.Parse(postID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	_, err = r.db.Exec(`
+        INSERT INTO post_followers (post_id, user_name)
+        SELECT id, $2 FROM posts WHERE id = $1
+        ON CONFLICT (post_id, user_name) DO NOTHING`,
+		postUUID, user)
+	return err
+}
+
+func (r *followRepository) UnfollowPost(postID, user string) error {
+	postUUID, err := uuid.Parse(postID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	_, err = r.db.Exec(`DELETE FROM post_followers WHERE post_id = $1 AND user_name = $2`, postUUID, user)
+	return err
+}
+
+func (r *followRepository) FollowThread(commentID, user string) error {
+	commentUUID, err := uuid.Parse(commentID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	_, err = r.db.Exec(`
+        INSERT INTO thread_followers (comment_id, user_name)
+        SELECT id, $2 FROM comments WHERE id = $1
+        ON CONFLICT (comment_id, user_name) DO NOTHING`,
+		commentUUID, user)
+	return err
+}
+
+func (r *followRepository) UnfollowThread(commentID, user string) error {
+	commentUUID, err := uuid.Parse(commentID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	_, err = r.db.Exec(`DELETE FROM thread_followers WHERE comment_id = $1 AND user_name = $2`, commentUUID, user)
+	return err
+}
+
+func (r *followRepository) CountPostFollowers(postIDs []string) (map[string]int, error) {
+	result := make(map[string]int)
+	ids := validUUIDs(postIDs)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT post_id, COUNT(*) FROM post_followers
+        WHERE post_id = ANY($1::uuid[])
+        GROUP BY post_id`,
+		pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postUUID uuid.UUID
+		var count int
+		if err := rows.Scan(&postUUID, &count); err != nil {
+			return nil, err
+		}
+		result[postUUID.String()] = count
+	}
+	return result, rows.Err()
+}
+
+func (r *followRepository) GetFollowedPosts(user string, postIDs []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	ids := validUUIDs(postIDs)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT post_id FROM post_followers
+        WHERE post_id = ANY($1::uuid[]) AND user_name = $2`,
+		pq.Array(ids), user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postUUID uuid.UUID
+		if err := rows.Scan(&postUUID); err != nil {
+			return nil, err
+		}
+		result[postUUID.String()] = true
+	}
+	return result, rows.Err()
+}
+
+func (r *followRepository) GetPostFollowers(postID string) ([]string, error) {
+	postUUID, err := uuid.Parse(postID)
+	if err != nil {
+		return nil, nil
+	}
+
+	return queryUsers(r.db, `
+        SELECT user_name FROM post_followers
+        WHERE post_id = $1
+        ORDER BY user_name COLLATE "C"`,
+		postUUID)
+}
+
+func (r *followRepository) GetThreadFollowers(commentIDs []string) ([]string, error) {
+	ids := validUUIDs(commentIDs)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return queryUsers(r.db, `
+        SELECT DISTINCT user_name COLLATE "C" FROM thread_followers
+        WHERE comment_id = ANY($1::uuid[])
+        ORDER BY 1`,
+		pq.Array(ids))
+}
+
+func queryUsers(db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []string
+	for rows.Next() {
+		var user string
+		if err := rows.Scan(&user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+const notificationColumns = `id, recipient, kind, actor, post_id, comment_id, created_at, read`
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) repositories.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var notification models.Notification
+	var dbUUID, postUUID, commentUUID uuid.UUID
+	var createdAt time.Time
+
+	if err := row.Scan(&dbUUID, &notification.Recipient, &notification.Kind, &notification.Actor,
+		&postUUID, &commentUUID, &createdAt, &notification.Read); err != nil {
+		return nil, err
+	}
+
+	notification.ID = dbUUID.String()
+	notification.PostID = postUUID.String()
+	notification.CommentID = commentUUID.String()
+	notification.CreatedAt = formatTime(createdAt)
+	return &notification, nil
+}
+
+func (r *notificationRepository) Create(notifications []*models.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, n := range notifications {
+		_, err := tx.Exec(`
+            INSERT INTO notifications (id, recipient, kind, actor, post_id, comment_id, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			n.ID, n.Recipient, n.Kind, n.Actor, n.PostID, n.CommentID, n.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *notificationRepository) List(recipient string, unreadOnly bool, page repositories.Page) ([]*models.Notification, bool, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE recipient = $1`
+	if unreadOnly {
+		query += ` AND NOT read`
+	}
+
+	clause, args := keysetClause(page, createdKeyset, true, []any{recipient})
+	rows, err := r.db.Query(query+clause, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := false
+	if len(notifications) > page.Limit {
+		hasMore = true
+		notifications = notifications[:page.Limit]
+	}
+	if page.Backward {
+		slices.Reverse(notifications)
+	}
+
+	return notifications, hasMore, nil
+}
+
+func (r *notificationRepository) CountUnread(recipient string) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE recipient = $1 AND NOT read`, recipient).Scan(&count)
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(recipient string, ids []string) (int, error) {
+	valid := validUUIDs(ids)
+	if len(valid) == 0 {
+		return 0, nil
+	}
+
+	res, err := r.db.Exec(`
+        UPDATE notifications SET read = TRUE
+        WHERE recipient = $1 AND id = ANY($2::uuid[]) AND NOT read`,
+		recipient, pq.Array(valid))
+	if err != nil {
+		return 0, err
+	}
+
+	marked, err := res.RowsAffected()
+	return int(marked), err
+}
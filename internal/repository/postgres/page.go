@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"fmt"
+	"posts_comments_service/internal/domain/constants"
+	"strings"
+	"time"
+
+	"posts_comments_service/internal/domain/repositories"
+)
+
+// keyset is the ordering of a list: its sort columns with the id as the
+// last one, their SQL types, and how a cursor supplies their values.
+type keyset struct {
+	columns []string
+	types   []string
+	values  func(cursor *repositories.Cursor) []any
+}
+
+var (
+	createdKeyset = keyset{
+		columns: []string{"created_at", "id"},
+		types:   []string{"timestamptz", "uuid"},
+		values:  func(c *repositories.Cursor) []any { return []any{c.CreatedAt, c.ID} },
+	}
+	topKeyset = keyset{
+		columns: []string{"score", "created_at", "id"},
+		types:   []string{"integer", "timestamptz", "uuid"},
+		values:  func(c *repositories.Cursor) []any { return []any{c.Score, c.CreatedAt, c.ID} },
+	}
+	hotKeyset = keyset{
+		columns: []string{"hot", "id"},
+		types:   []string{"double precision", "uuid"},
+		values:  func(c *repositories.Cursor) []any { return []any{c.Hot, c.ID} },
+	}
+	activityKeyset = keyset{
+		columns: []string{"last_activity_at", "id"},
+		types:   []string{"timestamptz", "uuid"},
+		values:  func(c *repositories.Cursor) []any { return []any{c.LastActivityAt, c.ID} },
+	}
+	// pinnedKeyset orders the pinned comments of a post, first pinned first.
+	pinnedKeyset = keyset{
+		columns: []string{"pinned_at", "id"},
+		types:   []string{"timestamptz", "uuid"},
+		values:  func(c *repositories.Cursor) []any { return []any{c.PinnedAt, c.ID} },
+	}
+)
+
+// commentKeyset returns the ordering of a comment sort order and whether it
+// is descending. The ranked orders list the best comments first.
+func commentKeyset(sortOrder string) (keyset, bool) {
+	switch sortOrder {
+	case constants.SortAsc:
+		return createdKeyset, false
+	case constants.SortTop:
+		return topKeyset, true
+	case constants.SortHot:
+		return hotKeyset, true
+	case constants.SortLastActivity:
+		return activityKeyset, true
+	default:
+		return createdKeyset, true
+	}
+}
+
+// orderBy renders the ORDER BY list, every column in the same direction.
+func (k keyset) orderBy(direction string) string {
+	parts := make([]string, len(k.columns))
+	for i, column := range k.columns {
+		parts[i] = column + " " + direction
+	}
+	return strings.Join(parts, ", ")
+}
+
+// condition compares the row key with the cursor key and appends the
+// cursor values to args.
+func (k keyset) condition(op string, cursor *repositories.Cursor, args []any) (string, []any) {
+	placeholders := make([]string, len(k.columns))
+	for i, value := range k.values(cursor) {
+		args = append(args, value)
+		placeholders[i] = fmt.Sprintf("$%d::%s", len(args), k.types[i])
+	}
+	return fmt.Sprintf("(%s) %s (%s)", strings.Join(k.columns, ", "), op, strings.Join(placeholders, ", ")), args
+}
+
+// keysetClause renders the cursor conditions, ORDER BY and LIMIT of a page
+// over the keyset and appends their arguments. The id breaks ties between
+// rows with equal sort values. One row more than the page size is requested
+// to find out whether the page has a continuation.
+// Backward pages are fetched in reverse and have to be flipped back by the caller.
+func keysetClause(page repositories.Page, key keyset, desc bool, args []any) (string, []any) {
+	afterOp, beforeOp := ">", "<"
+	if desc {
+		afterOp, beforeOp = "<", ">"
+	}
+
+	direction := constants.SortAsc
+	if desc != page.Backward {
+		direction = constants.SortDesc
+	}
+
+	var clause strings.Builder
+	if page.After != nil {
+		var condition string
+		condition, args = key.condition(afterOp, page.After, args)
+		clause.WriteString(" AND " + condition)
+	}
+	if page.Before != nil {
+		var condition string
+		condition, args = key.condition(beforeOp, page.Before, args)
+		clause.WriteString(" AND " + condition)
+	}
+
+	args = append(args, page.Limit+1)
+	fmt.Fprintf(&clause, " ORDER BY %s LIMIT $%d", key.orderBy(direction), len(args))
+
+	return clause.String(), args
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(constants.TimeFormat)
+}
@@ -2,18 +2,80 @@ package postgres
 
 import (
 	"database/sql"
+	"fmt"
 	"posts_comments_service/internal/domain/constants"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"posts_comments_service/internal/domain/models"
 	"posts_comments_service/internal/domain/repositories"
+	"posts_comments_service/internal/domain/votes"
 )
 
+const postColumns = `id, title, content, author, allow_comments, created_at, comments_closed_by, comments_closed_at, max_comment_depth, comments_total, score, upvotes, downvotes, archived_at, tags, category`
+
 type postRepository struct {
 	db *sql.DB
 }
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type extraScanner struct {
+	row   rowScanner
+	extra []any
+}
+
+func (s extraScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// withExtra lets the scan helpers read rows that carry additional trailing columns.
+func withExtra(row rowScanner, extra ...any) rowScanner {
+	return extraScanner{row: row, extra: extra}
+}
+
+func scanPost(row rowScanner) (*models.Post, error) {
+	var post models.Post
+	var dbUUID uuid.UUID
+	var createdAt time.Time
+	var closedBy sql.NullString
+	var closedAt sql.NullTime
+	var maxDepth sql.NullInt64
+	var archivedAt sql.NullTime
+	var category sql.NullString
+
+	if err := row.Scan(&dbUUID, &post.Title, &post.Content, &post.Author, &post.AllowComments, &createdAt, &closedBy, &closedAt, &maxDepth, &post.CommentsTotal,
+		&post.Score, &post.Upvotes, &post.Downvotes, &archivedAt, pq.Array(&post.Tags), &category); err != nil {
+		return nil, err
+	}
+
+	post.ID = dbUUID.String()
+	post.CreatedAt = formatTime(createdAt)
+	if closedBy.Valid {
+		post.CommentsClosedBy = &closedBy.String
+	}
+	if closedAt.Valid {
+		formatted := formatTime(closedAt.Time)
+		post.CommentsClosedAt = &formatted
+	}
+	if maxDepth.Valid {
+		depth := int(maxDepth.Int64)
+		post.MaxCommentDepth = &depth
+	}
+	if archivedAt.Valid {
+		formatted := formatTime(archivedAt.Time)
+		post.ArchivedAt = &formatted
+	}
+	if category.Valid {
+		post.Category = &category.String
+	}
+	return &post, nil
+}
+
 func NewPostRepository(db *sql.DB) repositories.PostRepository {
 	return &postRepository{db: db}
 }
@@ -35,79 +97,315 @@ func (r *postRepository) GetByID(id string) (*models.Post, error) {
 		return nil, repositories.ErrNotFound
 	}
 
-	row := r.db.QueryRow(`
-        SELECT id, title, content, author, allow_comments, created_at
-        FROM posts WHERE id = $1`, postUUID)
+	row := r.db.QueryRow(`SELECT `+postColumns+` FROM posts WHERE id = $1`, postUUID)
 
-	var post models.Post
-	var dbUUID uuid.UUID
-	var createdAt time.Time
+	post, err := scanPost(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repositories.ErrNotFound
+		}
+		return nil, err
+	}
+	return post, nil
+}
+
+func (r *postRepository) GetByIDs(ids []string) (map[string]*models.Post, error) {
+	result := make(map[string]*models.Post, len(ids))
+	valid := validUUIDs(ids)
+	if len(valid) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Query(`SELECT `+postColumns+` FROM posts WHERE id = ANY($1::uuid[])`, pq.Array(valid))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[post.ID] = post
+	}
+	return result, rows.Err()
+}
+
+func (r *postRepository) Update(post *models.Post) error {
+	postUUID, err := uuid.Parse(post.ID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	res, err := r.db.Exec(`
+        UPDATE posts SET title = $2, content = $3
+        WHERE id = $1`,
+		postUUID, post.Title, post.Content)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+func (r *postRepository) SetCommentsEnabled(id string, enabled bool, closedBy *string, closedAt *string) error {
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	res, err := r.db.Exec(`
+        UPDATE posts SET allow_comments = $2, comments_closed_by = $3, comments_closed_at = $4
+        WHERE id = $1`,
+		postUUID, enabled, closedBy, closedAt)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+func (r *postRepository) Archive(createdBefore, archivedAt string) (int, error) {
+	res, err := r.db.Exec(`
+        UPDATE posts SET archived_at = $2
+        WHERE archived_at IS NULL AND created_at < $1`,
+		createdBefore, archivedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	archived, err := res.RowsAffected()
+	return int(archived), err
+}
+
+func (r *postRepository) SetMaxCommentDepth(id string, maxDepth *int) error {
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	res, err := r.db.Exec(`UPDATE posts SET max_comment_depth = $2 WHERE id = $1`, postUUID, maxDepth)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+// Delete removes the post; its comments and votes are removed by ON DELETE CASCADE.
+func (r *postRepository) Delete(id string) error {
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	res, err := r.db.Exec(`DELETE FROM posts WHERE id = $1`, postUUID)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+// Vote locks the post row, records the vote and adjusts the counters.
+func (r *postRepository) Vote(id string, voter string, value int) (*models.VoteTally, error) {
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repositories.ErrNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	if err := row.Scan(&dbUUID, &post.Title, &post.Content, &post.Author, &post.AllowComments, &createdAt); err != nil {
+	var upvotes, downvotes int
+	err = tx.QueryRow(`SELECT upvotes, downvotes FROM posts WHERE id = $1 FOR UPDATE`, postUUID).Scan(&upvotes, &downvotes)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, repositories.ErrNotFound
 		}
 		return nil, err
 	}
 
-	post.ID = dbUUID.String()
-	post.CreatedAt = createdAt.Format(time.RFC3339)
-	return &post, nil
+	up, down, err := castVote(tx, postVotes, postUUID, voter, value)
+	if err != nil {
+		return nil, err
+	}
+	upvotes += up
+	downvotes += down
+
+	_, err = tx.Exec(`
+        UPDATE posts SET upvotes = $2, downvotes = $3, score = $4
+        WHERE id = $1`,
+		postUUID, upvotes, downvotes, upvotes-downvotes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return votes.Tally(id, upvotes, downvotes, value), nil
+}
+
+func (r *postRepository) GetVotes(voter string, ids []string) (map[string]int, error) {
+	return getVotes(r.db, postVotes, voter, ids)
 }
 
-func (r *postRepository) List(limit int, after *string, sortOrder string) ([]*models.Post, error) {
-	var query string
-	var afterTime *time.Time
-
-	if after != nil {
-		id, err := uuid.Parse(*after)
-		if err == nil {
-			row := r.db.QueryRow("SELECT created_at FROM posts WHERE id = $1", id)
-			var t time.Time
-			if err := row.Scan(&t); err == nil {
-				afterTime = &t
-			} else if err == sql.ErrNoRows {
-				return nil, repositories.ErrInvalidCursor
-			}
+// SetTags replaces the tags of the post and their index rows.
+func (r *postRepository) SetTags(id string, tags []string) error {
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE posts SET tags = $2 WHERE id = $1`, postUUID, pq.Array(tags))
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM post_tags WHERE post_id = $1`, postUUID); err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		_, err = tx.Exec(`
+            INSERT INTO post_tags (post_id, tag)
+            SELECT $1, unnest($2::text[])
+            ON CONFLICT DO NOTHING`,
+			postUUID, pq.Array(tags))
+		if err != nil {
+			return err
 		}
 	}
 
-	if sortOrder == constants.SortAsc {
-		query = `
-            SELECT id, title, content, author, allow_comments, created_at
-            FROM posts
-            WHERE ($1::timestamptz IS NULL OR created_at > $1)
-            ORDER BY created_at ASC
-            LIMIT $2`
-	} else {
-		query = `
-            SELECT id, title, content, author, allow_comments, created_at
-            FROM posts
-            WHERE ($1::timestamptz IS NULL OR created_at < $1)
-            ORDER BY created_at DESC
-            LIMIT $2`
+	return tx.Commit()
+}
+
+func (r *postRepository) SetCategory(id string, category *string) error {
+	postUUID, err := uuid.Parse(id)
+	if err != nil {
+		return repositories.ErrNotFound
 	}
 
-	rows, err := r.db.Query(query, afterTime, limit)
+	res, err := r.db.Exec(`UPDATE posts SET category = $2 WHERE id = $1`, postUUID, category)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+func (r *postRepository) ListTags(prefix string, limit int) ([]*models.TagCount, error) {
+	rows, err := r.db.Query(`
+        SELECT tag, COUNT(*) FROM post_tags
+        WHERE starts_with(tag, $1)
+        GROUP BY tag
+        ORDER BY COUNT(*) DESC, tag COLLATE "C"
+        LIMIT $2`,
+		prefix, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var posts []*models.Post
+	tags := make([]*models.TagCount, 0)
 	for rows.Next() {
-		var post models.Post
-		var dbUUID uuid.UUID
-		var createdAt time.Time
-
-		if err := rows.Scan(&dbUUID, &post.Title, &post.Content, &post.Author, &post.AllowComments, &createdAt); err != nil {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
 			return nil, err
 		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
+
+// filterClause renders the conditions of the filter and appends their arguments.
+func filterClause(filter repositories.PostFilter, args []any) (string, []any) {
+	var clause string
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		clause += fmt.Sprintf(" AND category = $%d", len(args))
+	}
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		clause += fmt.Sprintf(" AND id IN (SELECT post_id FROM post_tags WHERE tag = $%d)", len(args))
+	}
+	return clause, args
+}
+
+func (r *postRepository) List(filter repositories.PostFilter, page repositories.Page) ([]*models.Post, bool, error) {
+	condition, args := filterClause(filter, nil)
+	clause, args := keysetClause(page, createdKeyset, page.SortOrder != constants.SortAsc, args)
+	query := `SELECT ` + postColumns + ` FROM posts WHERE TRUE` + condition + clause
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		posts = append(posts, post)
+	}
+
+	hasMore := false
+	if len(posts) > page.Limit {
+		hasMore = true
+		posts = posts[:page.Limit]
+	}
+	if page.Backward {
+		slices.Reverse(posts)
+	}
+
+	return posts, hasMore, nil
+}
 
-		post.ID = dbUUID.String()
-		post.CreatedAt = createdAt.Format(time.RFC3339)
-		posts = append(posts, &post)
+func (r *postRepository) Count(filter repositories.PostFilter) (int, error) {
+	condition, args := filterClause(filter, nil)
+
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM posts WHERE TRUE`+condition, args...).Scan(&count); err != nil {
+		return 0, err
 	}
+	return count, nil
+}
 
-	return posts, nil
+func expectAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+// validUUIDs drops the ids that are not UUIDs: no row can have them, and
+// the uuid[] cast would reject the whole query.
+func validUUIDs(ids []string) []string {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	return valid
 }
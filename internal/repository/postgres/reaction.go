@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"posts_comments_service/internal/domain/models"
+	"posts_comments_service/internal/domain/repositories"
+)
+
+type reactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) repositories.ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+func (r *reactionRepository) Add(commentID, emoji, user string) error {
+	commentUUID, err := uuid.Parse(commentID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	res, err := r.db.Exec(`
+        INSERT INTO comment_reactions (comment_id, emoji, user_name)
+        SELECT id, $2, $3 FROM comments WHERE id = $1
+        ON CONFLICT (comment_id, emoji, user_name) DO NOTHING`,
+		commentUUID, emoji, user)
+	if err != nil {
+		return err
+	}
+
+	// Nothing inserted means either an existing reaction or no comment.
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM comments WHERE id = $1)`, commentUUID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return repositories.ErrNotFound
+	}
+	return nil
+}
+
+func (r *reactionRepository) Remove(commentID, emoji, user string) error {
+	commentUUID, err := uuid.Parse(commentID)
+	if err != nil {
+		return repositories.ErrNotFound
+	}
+
+	_, err = r.db.Exec(`
+        DELETE FROM comment_reactions
+        WHERE comment_id = $1 AND emoji = $2 AND user_name = $3`,
+		commentUUID, emoji, user)
+	return err
+}
+
+func (r *reactionRepository) GetSummaries(commentIDs []string, user string) (map[string][]*models.ReactionSummary, error) {
+	result := make(map[string][]*models.ReactionSummary)
+	ids := validUUIDs(commentIDs)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT comment_id, emoji, COUNT(*), BOOL_OR(user_name = $2)
+        FROM comment_reactions
+        WHERE comment_id = ANY($1::uuid[])
+        GROUP BY comment_id, emoji
+        ORDER BY comment_id, COUNT(*) DESC, emoji COLLATE "C"`,
+		pq.Array(ids), user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentUUID uuid.UUID
+		var summary models.ReactionSummary
+		if err := rows.Scan(&commentUUID, &summary.Emoji, &summary.Count, &summary.ReactedByMe); err != nil {
+			return nil, err
+		}
+		id := commentUUID.String()
+		result[id] = append(result[id], &summary)
+	}
+	return result, rows.Err()
+}
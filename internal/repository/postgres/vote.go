@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"posts_comments_service/internal/domain/votes"
+)
+
+// voteTable is the table holding the votes on one kind of target and the
+// column referencing the target.
+type voteTable struct {
+	name   string
+	target string
+}
+
+var (
+	postVotes    = voteTable{name: "post_votes", target: "post_id"}
+	commentVotes = voteTable{name: "comment_votes", target: "comment_id"}
+)
+
+// castVote replaces the vote of the voter on the target inside tx and
+// returns the resulting change of the upvote and downvote counters. The
+// caller must hold the row lock of the target, which serializes the votes
+// cast on it.
+func castVote(tx *sql.Tx, table voteTable, targetID uuid.UUID, voter string, value int) (int, int, error) {
+	var previous int
+	err := tx.QueryRow(`SELECT value FROM `+table.name+` WHERE `+table.target+` = $1 AND voter = $2`, targetID, voter).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, 0, err
+	}
+
+	if value == 0 {
+		_, err = tx.Exec(`DELETE FROM `+table.name+` WHERE `+table.target+` = $1 AND voter = $2`, targetID, voter)
+	} else {
+		_, err = tx.Exec(`
+            INSERT INTO `+table.name+` (`+table.target+`, voter, value)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (`+table.target+`, voter) DO UPDATE SET value = EXCLUDED.value`,
+			targetID, voter, value)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	up, down := votes.Deltas(previous, value)
+	return up, down, nil
+}
+
+// getVotes reads the votes of the voter on any of the targets.
+func getVotes(db *sql.DB, table voteTable, voter string, ids []string) (map[string]int, error) {
+	result := make(map[string]int)
+	targets := validUUIDs(ids)
+	if len(targets) == 0 {
+		return result, nil
+	}
+
+	rows, err := db.Query(`
+        SELECT `+table.target+`, value
+        FROM `+table.name+`
+        WHERE voter = $1 AND `+table.target+` = ANY($2::uuid[])`,
+		voter, pq.Array(targets))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var targetID uuid.UUID
+		var value int
+		if err := rows.Scan(&targetID, &value); err != nil {
+			return nil, err
+		}
+		result[targetID.String()] = value
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS comments_closed_at,
+    DROP COLUMN IF EXISTS comments_closed_by;
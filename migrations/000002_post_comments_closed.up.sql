@@ -0,0 +1,3 @@
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS comments_closed_by TEXT,
+    ADD COLUMN IF NOT EXISTS comments_closed_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_comment_revisions_comment_id;
+
+DROP TABLE IF EXISTS comment_revisions;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
@@ -0,0 +1,10 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    text TEXT NOT NULL CHECK (char_length(text) <= 2000),
+    created_at TIMESTAMPTZ NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_comment_revisions_comment_id ON comment_revisions(comment_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_comments_thread_order;
+
+DROP INDEX IF EXISTS idx_posts_created_at_id;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts(created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_comments_thread_order ON comments(post_id, parent_id, created_at, id);
@@ -0,0 +1,4 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS replies_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE comments c
+SET replies_count = (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id);
@@ -0,0 +1,2 @@
+ALTER TABLE comments DROP COLUMN IF EXISTS depth;
+ALTER TABLE posts DROP COLUMN IF EXISTS max_comment_depth;
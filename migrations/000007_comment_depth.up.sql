@@ -0,0 +1,13 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS max_comment_depth INTEGER CHECK (max_comment_depth >= 0);
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 1;
+
+WITH RECURSIVE levels (id, depth) AS (
+    SELECT id, 1 FROM comments WHERE parent_id IS NULL
+  UNION ALL
+    SELECT c.id, levels.depth + 1
+    FROM comments c
+    JOIN levels ON c.parent_id = levels.id
+)
+UPDATE comments SET depth = levels.depth
+FROM levels
+WHERE comments.id = levels.id;
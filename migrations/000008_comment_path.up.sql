@@ -0,0 +1,16 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS path TEXT;
+
+WITH RECURSIVE paths (id, path) AS (
+    SELECT id, id::text FROM comments WHERE parent_id IS NULL
+  UNION ALL
+    SELECT c.id, paths.path || '.' || c.id::text
+    FROM comments c
+    JOIN paths ON c.parent_id = paths.id
+)
+UPDATE comments SET path = paths.path
+FROM paths
+WHERE comments.id = paths.id;
+
+ALTER TABLE comments ALTER COLUMN path SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_path ON comments (path text_pattern_ops);
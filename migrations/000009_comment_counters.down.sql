@@ -0,0 +1,2 @@
+ALTER TABLE comments DROP COLUMN IF EXISTS descendants_count;
+ALTER TABLE posts DROP COLUMN IF EXISTS comments_total;
@@ -0,0 +1,8 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comments_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS descendants_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE posts p
+SET comments_total = (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id);
+
+UPDATE comments c
+SET descendants_count = (SELECT COUNT(*) FROM comments d WHERE d.path LIKE c.path || '.%');
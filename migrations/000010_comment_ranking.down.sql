@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_comments_thread_activity;
+DROP INDEX IF EXISTS idx_comments_thread_hot;
+DROP INDEX IF EXISTS idx_comments_thread_top;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS last_activity_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS hot;
+ALTER TABLE comments DROP COLUMN IF EXISTS score;
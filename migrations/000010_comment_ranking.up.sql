@@ -0,0 +1,19 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS hot DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ;
+
+-- Same formula as ranking.Hot for a score of zero.
+UPDATE comments SET hot = (EXTRACT(EPOCH FROM created_at) - 1134028003) / 45000;
+
+UPDATE comments c
+SET last_activity_at = (
+    SELECT MAX(d.created_at)
+    FROM comments d
+    WHERE d.id = c.id OR d.path LIKE c.path || '.%'
+);
+
+ALTER TABLE comments ALTER COLUMN last_activity_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_thread_top ON comments(post_id, parent_id, score, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_comments_thread_hot ON comments(post_id, parent_id, hot, id);
+CREATE INDEX IF NOT EXISTS idx_comments_thread_activity ON comments(post_id, parent_id, last_activity_at, id);
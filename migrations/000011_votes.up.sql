@@ -0,0 +1,26 @@
+-- One vote per voter and target. Votes on posts and on comments live in
+-- separate tables so that each can reference its target and be removed
+-- together with it.
+CREATE TABLE IF NOT EXISTS post_votes (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    voter TEXT NOT NULL,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    PRIMARY KEY (post_id, voter)
+);
+
+CREATE TABLE IF NOT EXISTS comment_votes (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    voter TEXT NOT NULL,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    PRIMARY KEY (comment_id, voter)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_votes_voter ON post_votes(voter, post_id);
+CREATE INDEX IF NOT EXISTS idx_comment_votes_voter ON comment_votes(voter, comment_id);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS upvotes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS downvotes INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS upvotes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS downvotes INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- One reaction per user, comment and emoji.
+CREATE TABLE IF NOT EXISTS comment_reactions (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    user_name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, emoji, user_name)
+);
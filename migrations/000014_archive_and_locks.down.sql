@@ -0,0 +1,3 @@
+ALTER TABLE comments DROP COLUMN IF EXISTS locked;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,3 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS locked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,33 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS mentions TEXT[] NOT NULL DEFAULT '{}';
+
+-- Looks up the comments that mention a user.
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_name TEXT NOT NULL,
+    PRIMARY KEY (comment_id, user_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_user ON comment_mentions(user_name, comment_id);
+
+-- Fill in the mentions of the existing comments. The pattern follows
+-- internal/domain/mentions, but [[:alnum:]] depends on the LC_CTYPE of the
+-- database: under a UTF-8 locale it matches letters and digits of any
+-- script, under C only ASCII ones, so other names are not found there.
+UPDATE comments c SET mentions = found.names
+FROM (
+    SELECT comment_id, array_agg(user_name ORDER BY first_position) AS names
+    FROM (
+        SELECT src.id AS comment_id, m.match[1] AS user_name, MIN(m.position) AS first_position
+        FROM comments src
+        CROSS JOIN LATERAL regexp_matches(src.text, '(?:^|[^[:alnum:]_@])@([[:alnum:]_]+)', 'g')
+            WITH ORDINALITY AS m(match, position)
+        WHERE NOT src.deleted
+        GROUP BY src.id, m.match[1]
+    ) matches
+    GROUP BY comment_id
+) found
+WHERE c.id = found.comment_id;
+
+INSERT INTO comment_mentions (comment_id, user_name)
+SELECT id, unnest(mentions) FROM comments WHERE mentions <> '{}'
+ON CONFLICT DO NOTHING;
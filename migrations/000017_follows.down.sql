@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS thread_followers;
+DROP TABLE IF EXISTS post_followers;
@@ -0,0 +1,14 @@
+-- Followers of a post and of a comment thread, notified about new comments.
+CREATE TABLE IF NOT EXISTS post_followers (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_name)
+);
+
+CREATE TABLE IF NOT EXISTS thread_followers (
+    comment_id UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_name)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS post_tags;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS category;
+ALTER TABLE posts DROP COLUMN IF EXISTS tags;